@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Gornak40/algolymp/config"
+	"github.com/Gornak40/algolymp/polygon/bober"
+	"github.com/akamensky/argparse"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	parser := argparse.NewParser("bober", "Build Polygon package from local problem directory.")
+	pDir := parser.String("d", "dir", &argparse.Options{
+		Required: false,
+		Default:  ".",
+		Help:     "Problem directory",
+	})
+	output := parser.String("o", "output", &argparse.Options{
+		Required: true,
+		Help:     "Package directory or .zip archive",
+	})
+	convName := parser.String("c", "conventions", &argparse.Options{
+		Required: false,
+		Default:  "bober.json",
+		Help:     "Conventions file in problem directory",
+	})
+	if err := parser.Parse(os.Args); err != nil {
+		logrus.WithError(err).Fatal("bad arguments")
+	}
+
+	fsys := os.DirFS(*pDir)
+	conv, err := bober.LoadConventions(fsys, *convName)
+	if err != nil {
+		logrus.WithError(err).Fatal("bad conventions")
+	}
+	if conv.Name == "" {
+		abs, _ := filepath.Abs(*pDir)
+		conv.Name = filepath.Base(abs)
+	}
+	bob := bober.NewBober(fsys, conv)
+	bob.UseMapping(config.NewMapping())
+	pkg, err := bob.Build()
+	if err != nil {
+		logrus.WithError(err).Fatal("build failed")
+	}
+
+	if err := pkg.Write(*output); err != nil {
+		logrus.WithError(err).Fatal("failed to write package")
+	}
+	logrus.WithFields(logrus.Fields{"output": *output, "files": len(pkg)}).Info("success")
+}
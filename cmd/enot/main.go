@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+
+	"github.com/Gornak40/algolymp/config"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/enot"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/akamensky/argparse"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	modeExport  = "export"
+	modeImport  = "import"
+	defaultJobs = 4
+)
+
+func main() {
+	parser := argparse.NewParser("enot", "Convert Polygon package to other problem formats and back.")
+	format := parser.Selector("f", "format", []string{enot.FormatKattis, enot.FormatCMS}, &argparse.Options{
+		Required: true,
+		Help:     "Problem format",
+	})
+	mode := parser.Selector("m", "mode", []string{modeExport, modeImport}, &argparse.Options{
+		Required: false,
+		Default:  modeExport,
+		Help:     "Export Polygon package or import problem into Polygon",
+	})
+	pDir := parser.String("p", "prob-dir", &argparse.Options{
+		Required: false,
+		Default:  ".",
+		Help:     "Source problem directory or zip",
+	})
+	output := parser.String("o", "output", &argparse.Options{
+		Required: false,
+		Help:     "Output directory or .zip archive",
+	})
+	pID := parser.Int("i", "pid", &argparse.Options{
+		Required: false,
+		Help:     "Polygon problem ID to upload imported problem",
+	})
+	if err := parser.Parse(os.Args); err != nil {
+		logrus.WithError(err).Fatal("bad arguments")
+	}
+	if *output == "" && (*mode == modeExport || *pID == 0) {
+		logrus.Fatal("bad arguments: output is required")
+	}
+	fsys, closer, err := vydra.OpenPackage(*pDir)
+	if err != nil {
+		logrus.WithError(err).Fatal("bad problem package")
+	}
+	defer closer.Close()
+
+	mapping := config.NewMapping()
+	conv := enot.NewEnot(fsys)
+	conv.UseMapping(mapping)
+	var pkg vydra.Package
+	if *mode == modeExport {
+		pkg, err = conv.Export(*format)
+	} else {
+		pkg, err = conv.Import(*format)
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("conversion failed")
+	}
+	if *output != "" {
+		if err := pkg.Write(*output); err != nil {
+			logrus.WithError(err).Fatal("failed to write package")
+		}
+		logrus.WithFields(logrus.Fields{"output": *output, "files": len(pkg)}).Info("write package")
+	}
+	if *mode == modeImport && *pID != 0 {
+		upload(pkg, mapping, *pID)
+	}
+}
+
+func upload(pkg vydra.Package, mapping *polygon.Mapping, pID int) {
+	fsys, err := pkg.FS()
+	if err != nil {
+		logrus.WithError(err).Fatal("bad package")
+	}
+	cfg := config.NewConfig()
+	pClient := polygon.NewPolygon(&cfg.Polygon)
+	vyd := vydra.NewVydra(pClient, fsys, pID, cfg.Polygon.Legacy, defaultJobs)
+	vyd.UseMapping(mapping)
+	rep, err := vyd.Upload()
+	if rep != nil {
+		for _, err := range rep.Errors {
+			logrus.WithError(err).Error("vydra error")
+		}
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("upload failed")
+	}
+	if rep.Failed() != 0 {
+		logrus.WithField("count", rep.Failed()).Fatal("some steps failed")
+	}
+	logrus.WithField("pid", pID).Info("success upload")
+}
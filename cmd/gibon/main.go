@@ -1,7 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"text/tabwriter"
+	"time"
 
 	"github.com/Gornak40/algolymp/config"
 	"github.com/Gornak40/algolymp/polygon"
@@ -10,11 +13,15 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const defaultJobs = 4
+
 func main() {
 	methods := []string{
+		gibon.ModeBuild,
 		gibon.ModeContest,
 		gibon.ModeCommit,
 		gibon.ModeDownload,
+		gibon.ModeInfo,
 		gibon.ModePackage,
 		gibon.ModeUpdate,
 	}
@@ -28,6 +35,41 @@ func main() {
 		Required: true,
 		Help:     "Polygon method",
 	})
+	revision := parser.Int("r", "revision", &argparse.Options{
+		Required: false,
+		Help:     "Package revision to download or build (default: current)",
+	})
+	packType := parser.Selector("t", "type", []string{
+		polygon.PackageStandard, polygon.PackageLinux, polygon.PackageWindows,
+	}, &argparse.Options{
+		Required: false,
+		Default:  polygon.PackageLinux,
+		Help:     "Package type to download",
+	})
+	extract := parser.String("x", "extract", &argparse.Options{
+		Required: false,
+		Help:     "Extract package into directory instead of saving zip",
+	})
+	wait := parser.Int("w", "wait", &argparse.Options{
+		Required: false,
+		Default:  int(gibon.DefaultTimeout / time.Second),
+		Help:     "Package build waiting timeout in seconds",
+	})
+	isContest := parser.Flag("c", "contest", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Resolve method for all problems of the contest",
+	})
+	jobs := parser.Int("j", "jobs", &argparse.Options{
+		Required: false,
+		Default:  defaultJobs,
+		Help:     "Parallel problems count for contest",
+	})
+	isJSON := parser.Flag("J", "json", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Print info as JSON",
+	})
 	if err := parser.Parse(os.Args); err != nil {
 		logrus.WithError(err).Fatal("bad arguments")
 	}
@@ -35,9 +77,53 @@ func main() {
 	cfg := config.NewConfig()
 	pClient := polygon.NewPolygon(&cfg.Polygon)
 	gib := gibon.NewGibon(pClient, *pID)
+	gib.UseOptions(gibon.Options{
+		Revision: *revision,
+		Type:     *packType,
+		Extract:  *extract,
+		Timeout:  time.Duration(*wait) * time.Second,
+		JSON:     *isJSON,
+	})
 
+	if *isContest && *method == gibon.ModeInfo {
+		infos, err := gib.ContestInfo()
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to get contest info")
+		}
+		if err := gibon.WriteInfo(os.Stdout, infos, *isJSON); err != nil {
+			logrus.WithError(err).Fatal("failed to print info")
+		}
+
+		return
+	}
+	if *isContest {
+		resolveContest(gib, *method, *jobs)
+
+		return
+	}
 	if err := gib.Resolve(*method); err != nil {
 		logrus.WithError(err).Fatal("failed to resolve")
 	}
 	logrus.WithFields(logrus.Fields{"problem": *pID, "method": *method}).Info("success")
 }
+
+func resolveContest(gib *gibon.Gibon, method string, jobs int) {
+	results, err := gib.ResolveContest(method, jobs)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to resolve contest")
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd // padding
+	failed := 0
+	for _, res := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n",
+			res.Letter, res.ID, res.Name, res.Duration.Round(time.Millisecond), res.Status())
+		if res.Err != nil {
+			failed++
+		}
+	}
+	tw.Flush()
+	if failed != 0 {
+		logrus.WithFields(logrus.Fields{"failed": failed, "total": len(results)}).Fatal("contest resolve failed")
+	}
+	logrus.WithFields(logrus.Fields{"problems": len(results), "method": method}).Info("success")
+}
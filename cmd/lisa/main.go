@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Gornak40/algolymp/config"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/lisa"
+	"github.com/akamensky/argparse"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultStep = 5
+
+func main() {
+	parser := argparse.NewParser("lisa", "Set random balanced group scores using Polygon API.")
+	pID := parser.Int("i", "problem_id", &argparse.Options{
+		Required: true,
+		Help:     "Polygon problem ID",
+	})
+	total := parser.Int("t", "total", &argparse.Options{
+		Required: false,
+		Default:  polygon.FullProblemScore,
+		Help:     "Total problem score",
+	})
+	step := parser.Int("s", "step", &argparse.Options{
+		Required: false,
+		Default:  defaultStep,
+		Help:     "Group scores are multiples of step",
+	})
+	minScore := parser.Int("l", "min", &argparse.Options{
+		Required: false,
+		Default:  0,
+		Help:     "Minimal group score",
+	})
+	maxScore := parser.Int("u", "max", &argparse.Options{
+		Required: false,
+		Default:  0,
+		Help:     "Maximal group score (default: total)",
+	})
+	monotonic := parser.Flag("m", "monotonic", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Group scores don't decrease with group order",
+	})
+	policy := parser.Selector("p", "policy", []string{polygon.PolicyCompleteGroup, polygon.PolicyEachTest}, &argparse.Options{
+		Required: false,
+		Help:     "Set groups points policy",
+	})
+	seed := parser.Int("r", "seed", &argparse.Options{
+		Required: false,
+		Default:  -1, // unset, seed 0 must be reproducible too
+		Help:     "Random seed, negative means current time",
+	})
+	preview := parser.Flag("n", "preview", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Print scores without saving them",
+	})
+	if err := parser.Parse(os.Args); err != nil {
+		logrus.WithError(err).Fatal("bad arguments")
+	}
+	if *seed < 0 {
+		*seed = int(time.Now().UnixNano())
+	}
+	logrus.WithField("seed", *seed).Info("use random seed")
+
+	cfg := config.NewConfig()
+	pClient := polygon.NewPolygon(&cfg.Polygon)
+
+	l := lisa.NewLisa(pClient, *pID, int64(*seed))
+	groups, err := l.Generate(&lisa.Constraints{
+		Total:     *total,
+		Step:      *step,
+		Min:       *minScore,
+		Max:       *maxScore,
+		Monotonic: *monotonic,
+	}, *policy)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to generate scores")
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd // padding
+	fmt.Fprintln(tw, "group\ttests\tscore")
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", g.Name, len(g.Tests)+len(g.Samples), g.Score)
+	}
+	tw.Flush()
+	if *preview {
+		return
+	}
+	if err := l.Apply(groups, *policy); err != nil {
+		logrus.WithError(err).Fatal("failed to save scores")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Gornak40/algolymp/polygon/rys"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/akamensky/argparse"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	parser := argparse.NewParser("rys", "Lint Polygon package offline.")
+	pDir := parser.String("p", "prob-dir", &argparse.Options{
+		Required: false,
+		Default:  ".",
+		Help:     "Problem directory or package zip (with problem.xml)",
+	})
+	isJSON := parser.Flag("j", "json", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Print issues as JSON array",
+	})
+	if err := parser.Parse(os.Args); err != nil {
+		logrus.WithError(err).Fatal("bad arguments")
+	}
+	fsys, closer, err := vydra.OpenPackage(*pDir)
+	if err != nil {
+		logrus.WithError(err).Fatal("bad problem package")
+	}
+	defer closer.Close()
+
+	issues, err := rys.NewRys(fsys).Lint()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to read problem.xml")
+	}
+	if *isJSON {
+		if issues == nil {
+			issues = []rys.Issue{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(issues); err != nil {
+			logrus.WithError(err).Fatal("failed to encode issues")
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue) //nolint:forbidigo // Basic functionality.
+		}
+	}
+
+	errCount := 0
+	for _, issue := range issues {
+		if issue.Severity == rys.SeverityError {
+			errCount++
+		}
+	}
+	if errCount != 0 {
+		closer.Close()
+		logrus.WithFields(logrus.Fields{"errors": errCount, "total": len(issues)}).Fatal("package has errors")
+	}
+	logrus.WithField("warnings", len(issues)).Info("package is ok")
+}
@@ -5,12 +5,20 @@ import (
 
 	"github.com/Gornak40/algolymp/config"
 	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/scalp"
 	"github.com/akamensky/argparse"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
-	parser := argparse.NewParser("scalp", "Set incremental problem scoring using Polygon API.")
+	strategies := []string{
+		scalp.StrategyIncremental,
+		scalp.StrategyEqual,
+		scalp.StrategyWeighted,
+		scalp.StrategyGroups,
+	}
+
+	parser := argparse.NewParser("scalp", "Set problem scoring using Polygon API.")
 	pID := parser.Int("i", "problem_id", &argparse.Options{
 		Required: true,
 		Help:     "Polygon problem ID",
@@ -19,6 +27,25 @@ func main() {
 		Required: false,
 		Help:     "Include samples in scoring",
 	})
+	strategy := parser.Selector("m", "strategy", strategies, &argparse.Options{
+		Required: false,
+		Default:  scalp.StrategyIncremental,
+		Help:     "Scoring strategy",
+	})
+	spec := parser.String("g", "spec", &argparse.Options{
+		Required: false,
+		Help:     "Strategy spec, test ranges weights (1-5:1,6-10:2) or group points (1:20,2:30,3:50)",
+	})
+	total := parser.Int("t", "total", &argparse.Options{
+		Required: false,
+		Default:  polygon.FullProblemScore,
+		Help:     "Total problem score",
+	})
+	preview := parser.Flag("n", "preview", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Print points without saving them",
+	})
 	if err := parser.Parse(os.Args); err != nil {
 		logrus.WithError(err).Fatal("bad arguments")
 	}
@@ -26,7 +53,18 @@ func main() {
 	cfg := config.NewConfig()
 	pClient := polygon.NewPolygon(&cfg.Polygon)
 
-	if err := pClient.IncrementalScoring(*pID, *samples); err != nil {
+	sc := scalp.NewScalp(pClient, *pID, *total, *samples)
+	plan, err := sc.Plan(*strategy, *spec)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to build scoring")
+	}
+	if err := scalp.WritePlan(os.Stdout, plan); err != nil {
+		logrus.WithError(err).Fatal("failed to print scoring")
+	}
+	if *preview {
+		return
+	}
+	if err := sc.Apply(plan); err != nil {
 		logrus.WithError(err).Fatal("failed set scoring")
 	}
 }
@@ -1,9 +1,9 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Gornak40/algolymp/config"
 	"github.com/Gornak40/algolymp/polygon"
@@ -13,16 +13,6 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-const (
-	universalTag = "universal"
-	moscowTag    = "moscow"
-	kalugaTag    = "kaluga"
-)
-
-var (
-	ErrUnknownTexTable = errors.New("unknown textable")
-)
-
 func main() {
 	parser := argparse.NewParser("valeria", "Build valuer + textable using Polygon API.")
 	pID := parser.Int("i", "problem_id", &argparse.Options{
@@ -34,12 +24,12 @@ func main() {
 		Help:     "Print valuer.cfg in stderr",
 	})
 	tableTyp := parser.Selector("t", "textable-type", []string{
-		universalTag,
-		moscowTag,
-		kalugaTag,
+		textables.TypeUniversal,
+		textables.TypeMoscow,
+		textables.TypeKaluga,
 	}, &argparse.Options{
 		Required: false,
-		Default:  universalTag,
+		Default:  textables.TypeUniversal,
 		Help:     "Textable type",
 	})
 	vars := parser.StringList("c", "variable", &argparse.Options{
@@ -47,6 +37,11 @@ func main() {
 		Default:  nil,
 		Help:     "Variables list (useful for some textables)",
 	})
+	statements := parser.StringList("s", "statement", &argparse.Options{
+		Required: false,
+		Default:  nil,
+		Help:     "Save textable into scoring of statement language, optionally with type (english:kaluga)",
+	})
 	if err := parser.Parse(os.Args); err != nil {
 		logrus.WithError(err).Fatal("bad arguments")
 	}
@@ -56,18 +51,21 @@ func main() {
 	val := valeria.NewValeria(pClient)
 
 	logrus.WithFields(logrus.Fields{"type": *tableTyp, "vars": *vars}).Info("select textable")
-	var table textables.Table
-	switch *tableTyp {
-	case universalTag:
-		table = new(textables.UniversalTable)
-	case moscowTag:
-		table = textables.NewMoscowTable(*vars)
-	case kalugaTag:
-		table = new(textables.KalugaTable)
+	table, err := textables.NewTable(*tableTyp, textables.Russian, *vars)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to get textable")
 	}
-	if table == nil {
-		logrus.WithError(ErrUnknownTexTable).Fatal("failed to get textable")
+	tables := make(map[string]textables.Table, len(*statements))
+	for _, st := range *statements {
+		lang, typ, ok := strings.Cut(st, ":")
+		if !ok {
+			typ = *tableTyp
+		}
+		if tables[lang], err = textables.NewTable(typ, textables.Language(lang), *vars); err != nil {
+			logrus.WithError(err).WithField("language", lang).Fatal("failed to get textable")
+		}
 	}
+	val.UseStatements(tables)
 
 	if err := val.InformaticsValuer(*pID, table, *verbose); err != nil {
 		logrus.WithError(err).Fatal("failed to get scoring")
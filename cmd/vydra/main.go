@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Gornak40/algolymp/config"
 	"github.com/Gornak40/algolymp/polygon"
@@ -10,6 +13,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	defaultJobs = 4
+)
+
 func main() {
 	parser := argparse.NewParser("vydra", "Upload package to Polygon.")
 	pID := parser.Int("i", "pid", &argparse.Options{
@@ -19,33 +26,112 @@ func main() {
 	pDir := parser.String("p", "prob-dir", &argparse.Options{
 		Required: false,
 		Default:  ".",
-		Help:     "Problem directory (with problem.xml)",
+		Help:     "Problem directory or package zip (with problem.xml)",
 	})
 	isLegacy := parser.Flag("l", "legacy", &argparse.Options{
 		Required: false,
 		Default:  false,
 		Help:     "Use legacy languages (for polygon lksh)",
 	})
+	jobs := parser.Int("j", "jobs", &argparse.Options{
+		Required: false,
+		Default:  defaultJobs,
+		Help:     "Parallel uploads count",
+	})
+	plan := parser.Flag("n", "plan", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Print API calls without touching Polygon",
+	})
+	isSync := parser.Flag("s", "sync", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Upload only the difference with a non-empty problem",
+	})
+	yes := parser.Flag("y", "yes", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Apply sync changes without confirmation",
+	})
+	prune := parser.Flag("d", "prune", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Clear statement sections missing in the package while syncing",
+	})
+	resume := parser.Flag("r", "resume", &argparse.Options{
+		Required: false,
+		Default:  false,
+		Help:     "Skip steps completed by the previous failed upload",
+	})
 	if err := parser.Parse(os.Args); err != nil {
 		logrus.WithError(err).Fatal("bad arguments")
 	}
-	if err := os.Chdir(*pDir); err != nil {
-		logrus.WithError(err).Fatal("bad problem directory")
+	fsys, closer, err := vydra.OpenPackage(*pDir)
+	if err != nil {
+		logrus.WithError(err).Fatal("bad problem package")
+	}
+	defer closer.Close()
+
+	if *plan {
+		vyd := vydra.NewVydra(nil, fsys, *pID, *isLegacy, *jobs)
+		vyd.UseMapping(config.NewMapping())
+		issues, err := vyd.Plan(os.Stdout)
+		if err != nil {
+			logrus.WithError(err).Fatal("plan failed")
+		}
+		if issues != 0 {
+			logrus.WithField("count", issues).Fatal("plan has issues")
+		}
+
+		return
 	}
 
 	cfg := config.NewConfig()
 	pClient := polygon.NewPolygon(&cfg.Polygon)
 
-	vyd := vydra.NewVydra(pClient, *pID, *isLegacy)
-	errs := make(chan error)
-	go func() {
-		for err := range errs {
-			if err != nil {
-				logrus.WithError(err).Error("vydra error")
-			}
+	vyd := vydra.NewVydra(pClient, fsys, *pID, *isLegacy || cfg.Polygon.Legacy, *jobs)
+	vyd.UseMapping(config.NewMapping())
+	var rep *vydra.Report
+	if *isSync {
+		vyd.UsePrune(*prune)
+		rep, err = vyd.Sync(func(changes []vydra.Change) bool {
+			return confirm(changes, *yes)
+		})
+	} else {
+		journalDir, jerr := vydra.DefaultJournalDir()
+		if jerr != nil {
+			logrus.WithError(jerr).Fatal("bad cache directory")
+		}
+		vyd.UseJournal(journalDir, *resume)
+		rep, err = vyd.Upload()
+	}
+	if rep != nil {
+		for _, s := range rep.Steps {
+			logrus.WithFields(logrus.Fields{
+				"ok": s.OK, "skipped": s.Skipped, "failed": s.Failed, "time": s.Duration,
+			}).Infof("report %s", s.Step)
 		}
-	}()
-	if err := vyd.Upload(errs); err != nil {
+		for _, err := range rep.Errors {
+			logrus.WithError(err).Error("vydra error")
+		}
+	}
+	if err != nil {
 		logrus.WithError(err).Fatal("upload failed")
 	}
+	if rep.Failed() != 0 {
+		logrus.WithField("count", rep.Failed()).Fatal("some steps failed, fix them and run with --resume")
+	}
+}
+
+func confirm(changes []vydra.Change, yes bool) bool {
+	for _, c := range changes {
+		fmt.Println(c) //nolint:forbidigo // Basic functionality.
+	}
+	if yes {
+		return true
+	}
+	fmt.Printf("Apply %d changes? [y/N] ", len(changes)) //nolint:forbidigo // Basic functionality.
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+	return strings.EqualFold(strings.TrimSpace(line), "y")
 }
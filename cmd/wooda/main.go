@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 
@@ -22,6 +23,7 @@ func main() {
 		wooda.ModeSolutionMain,
 		wooda.ModeSolutionCorrect,
 		wooda.ModeSolutionIncorrect,
+		wooda.ModeSolution,
 		wooda.ModeSample,
 		wooda.ModeImage,
 	}
@@ -39,13 +41,25 @@ func main() {
 		Required: true,
 		Help:     "Problem files glob",
 	})
+	tag := parser.String("t", "tag", &argparse.Options{
+		Required: false,
+		Help:     "Solution tag for sol mode (problem.xml name, e.g. runtime-error)",
+	})
 	if err := parser.Parse(os.Args); err != nil {
 		logrus.WithError(err).Fatal("bad arguments")
 	}
 
 	cfg := config.NewConfig()
+	mapping := config.NewMapping()
+	if *mode == wooda.ModeSolution {
+		if _, err := mapping.SolutionTag(*tag); err != nil {
+			logrus.WithError(err).Fatal("bad arguments")
+		}
+	}
 	pClient := polygon.NewPolygon(&cfg.Polygon)
 	wooda := wooda.NewWooda(pClient, *pID, *mode)
+	wooda.UseMapping(mapping)
+	wooda.UseTag(*tag)
 
 	files, err := filepath.Glob(*glob)
 	if err != nil {
@@ -62,7 +76,14 @@ func main() {
 
 	errCount := 0
 	for _, path := range files {
-		if err := wooda.Resolve(path); err != nil {
+		err := wooda.Resolve(path)
+		switch {
+		case err == nil:
+		case errors.Is(err, polygon.ErrAlreadyExists):
+			logrus.WithError(err).WithField("path", path).Warn("already exists, skip")
+		case errors.Is(err, polygon.ErrProblemNotFound), errors.Is(err, polygon.ErrAccessDenied):
+			logrus.WithError(err).Fatal("can't modify problem")
+		default:
 			errCount++
 			logrus.WithError(err).WithField("path", path).Error("failed to resolve")
 		}
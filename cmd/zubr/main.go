@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+
+	"github.com/Gornak40/algolymp/config"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/zubr"
+	"github.com/akamensky/argparse"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultJobs = 4
+)
+
+func main() {
+	parser := argparse.NewParser("zubr", "Migrate problem between Polygon instances.")
+	srcID := parser.Int("i", "src-pid", &argparse.Options{
+		Required: true,
+		Help:     "Source Polygon problem ID",
+	})
+	dstID := parser.Int("o", "dst-pid", &argparse.Options{
+		Required: true,
+		Help:     "Target Polygon problem ID (empty problem)",
+	})
+	srcName := parser.String("f", "from", &argparse.Options{
+		Required: true,
+		Help:     "Source Polygon instance name",
+	})
+	dstName := parser.String("t", "to", &argparse.Options{
+		Required: true,
+		Help:     "Target Polygon instance name",
+	})
+	jobs := parser.Int("j", "jobs", &argparse.Options{
+		Required: false,
+		Default:  defaultJobs,
+		Help:     "Parallel uploads count",
+	})
+	if err := parser.Parse(os.Args); err != nil {
+		logrus.WithError(err).Fatal("bad arguments")
+	}
+
+	cfg := config.NewConfig()
+	src := newInstance(cfg, *srcName)
+	dst := newInstance(cfg, *dstName)
+
+	zbr := zubr.NewZubr(src, dst, *jobs)
+	zbr.UseMapping(config.NewMapping())
+	rep, err := zbr.Migrate(*srcID, *dstID)
+	if rep != nil {
+		for _, err := range rep.Errors {
+			logrus.WithError(err).Error("vydra error")
+		}
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("migration failed")
+	}
+	logrus.WithFields(logrus.Fields{"from": *srcID, "to": *dstID}).Info("success")
+}
+
+func newInstance(cfg *config.Config, name string) *zubr.Instance {
+	pc, err := cfg.PolygonInstance(name)
+	if err != nil {
+		logrus.WithError(err).Fatal("bad config")
+	}
+
+	return &zubr.Instance{
+		Name:   name,
+		Client: polygon.NewPolygon(pc),
+		Legacy: pc.Legacy,
+	}
+}
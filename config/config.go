@@ -2,6 +2,8 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path"
 
@@ -10,15 +12,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+var ErrUnknownPolygon = errors.New("unknown polygon instance")
+
 type System struct {
 	Editor  string `json:"editor"`
 	Printer string `json:"printer"`
 }
 
 type Config struct {
-	Ejudge  ejudge.Config  `json:"ejudge"`
-	Polygon polygon.Config `json:"polygon"`
-	System  System         `json:"system"`
+	Ejudge   ejudge.Config             `json:"ejudge"`
+	Polygon  polygon.Config            `json:"polygon"`
+	Polygons map[string]polygon.Config `json:"polygons,omitempty"` // named instances
+	System   System                    `json:"system"`
 }
 
 func NewConfig() *Config {
@@ -35,3 +40,28 @@ func NewConfig() *Config {
 
 	return &cfg
 }
+
+// Embedded polygon mapping with overrides from mapping.json near the config.
+func NewMapping() *polygon.Mapping {
+	confDir, _ := os.UserConfigDir()
+	mapPath := path.Join(confDir, "algolymp", "mapping.json")
+	m, err := polygon.LoadMapping(mapPath)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to load mapping")
+	}
+
+	return m
+}
+
+// Named instance from polygons, the empty name means the default polygon.
+func (c *Config) PolygonInstance(name string) (*polygon.Config, error) {
+	if name == "" {
+		return &c.Polygon, nil
+	}
+	pc, ok := c.Polygons[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPolygon, name)
+	}
+
+	return &pc, nil
+}
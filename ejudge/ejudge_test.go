@@ -0,0 +1,156 @@
+package ejudge_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/Gornak40/algolymp/ejudge"
+	"github.com/Gornak40/algolymp/internal/fakejudge"
+	"github.com/stretchr/testify/require"
+)
+
+func masterLogin(t *testing.T, srv *fakejudge.Server, cid int) (*ejudge.Ejudge, string) {
+	t.Helper()
+	ej := ejudge.NewEjudge(srv.Config())
+	sid, err := ej.Login()
+	require.NoError(t, err)
+	csid, err := ej.MasterLogin(sid, cid)
+	require.NoError(t, err)
+
+	return ej, csid
+}
+
+func TestLogin(t *testing.T) {
+	t.Parallel()
+	srv := fakejudge.NewServer(t)
+	ej := ejudge.NewEjudge(srv.Config())
+
+	sid, err := ej.Login()
+	require.NoError(t, err)
+	require.NotEqual(t, ejudge.BadSID, sid)
+	require.NoError(t, ej.Logout(sid))
+	require.True(t, srv.LoggedOut())
+
+	cfg := srv.Config()
+	cfg.Password = "wrong"
+	sid, _ = ejudge.NewEjudge(cfg).Login()
+	require.Equal(t, ejudge.BadSID, sid)
+}
+
+func TestMasterLogin(t *testing.T) {
+	t.Parallel()
+	srv := fakejudge.NewServer(t)
+	ej := ejudge.NewEjudge(srv.Config())
+
+	sid, err := ej.Login()
+	require.NoError(t, err)
+	csid, err := ej.MasterLogin(sid, 47106)
+	require.NoError(t, err)
+	require.NotEmpty(t, csid)
+
+	_, err = ej.MasterLogin(ejudge.BadSID, 47106)
+	require.ErrorIs(t, err, ejudge.ErrBadStatusCode)
+}
+
+func TestServeControl(t *testing.T) {
+	t.Parallel()
+	srv := fakejudge.NewServer(t)
+	ej := ejudge.NewEjudge(srv.Config())
+	sid, err := ej.Login()
+	require.NoError(t, err)
+
+	require.NoError(t, ej.CreateContest(sid, 51011, 51000))
+	require.NoError(t, ej.Lock(sid, 51011))
+	require.NoError(t, ej.CheckContest(sid, 51011, true))
+	require.NoError(t, ej.Commit(sid))
+	require.NoError(t, ej.MakeVisible(sid, 51011))
+
+	contest := srv.Contest(51011)
+	require.Equal(t, 51000, contest.Created)
+	require.True(t, contest.Locked)
+	require.True(t, contest.Visible)
+	require.Equal(t, 1, srv.Committed())
+
+	require.NoError(t, ej.MakeInvisible(sid, 51011))
+	require.False(t, contest.Visible)
+}
+
+func TestFilterRuns(t *testing.T) {
+	t.Parallel()
+	srv := fakejudge.NewServer(t)
+	ej, csid := masterLogin(t, srv, 47106)
+
+	runs, err := ej.FilterRuns(csid, "prob == 'A'", 20)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 2, 0}, runs)
+
+	_, err = ej.FilterRuns(csid, "prob ==", 20)
+	require.ErrorIs(t, err, ejudge.ErrBadFilter)
+}
+
+func TestRuns(t *testing.T) {
+	t.Parallel()
+	srv := fakejudge.NewServer(t)
+	ej, csid := masterLogin(t, srv, 47110)
+
+	require.NoError(t, ej.ChangeRunStatus(csid, 8, "DQ"))
+	require.NoError(t, ej.ChangeRunStatus(csid, 9, "rejudge"))
+	require.ErrorIs(t, ej.ChangeRunStatus(csid, 8, "WA"), ejudge.ErrUnknownVerdict)
+	require.NoError(t, ej.SendRunComment(csid, 8, "remove #define int long long"))
+	require.NoError(t, ej.ReloadConfig(csid))
+
+	contest := srv.Contest(47110)
+	require.Equal(t, map[int]int{8: 10, 9: 99}, contest.Runs)
+	require.Equal(t, []string{"remove #define int long long"}, contest.Comments[8])
+	require.Equal(t, 1, contest.Reloaded)
+}
+
+func TestUsers(t *testing.T) {
+	t.Parallel()
+	srv := fakejudge.NewServer(t)
+	ej, csid := masterLogin(t, srv, 48501)
+
+	require.NoError(t, ej.RegisterUser(csid, "barmaley"))
+	require.NoError(t, ej.FlipUserVisible(csid, 12))
+	require.NoError(t, ej.FlipUserBan(csid, 12))
+	require.NoError(t, ej.FlipUserLock(csid, 12))
+	require.NoError(t, ej.FlipUserIncom(csid, 12))
+	require.NoError(t, ej.FlipUserPriv(csid, 12))
+	require.NoError(t, ej.FlipUserBan(csid, 12))
+
+	contest := srv.Contest(48501)
+	require.Equal(t, []string{"barmaley"}, contest.Registered)
+	require.Equal(t, &fakejudge.User{
+		Invisible:  true,
+		Locked:     true,
+		Incomplete: true,
+		Privileged: true,
+	}, contest.Users[12])
+}
+
+func TestDumps(t *testing.T) {
+	t.Parallel()
+	srv := fakejudge.NewServer(t)
+	ej, csid := masterLogin(t, srv, 436)
+
+	tests := []struct {
+		dump func(string) (io.Reader, error)
+		want string
+	}{
+		{ej.DumpUsers, "Id;Login;Name;Status\n12;myav;Mya V.;OK\n13;barmaley;Barmaley;OK\n"},
+		{ej.DumpRuns, "Run_Id;Time;Login;Prob;Lang;Stat_Short;Score\n" +
+			"0;1726575633;aibolit;A;python3;WA;0\n3;1726577472;barmaley;A;g++;OK;100\n"},
+		{ej.DumpStandings, "Place;User;A;B;Total;Penalty\n1;barmaley;+;+2;2;147\n2;aibolit;+;-3;1;20\n"},
+		{ej.DumpProbStats, "Problem;Total;Accepted\nA;17;12\nB;9;1\n"},
+		{ej.DumpRegPasswords, "User Id;User login;User name;Flags;Password;Location\n" +
+			"12;myav;Mya V.;;qwerty12;Moscow\n"},
+		{ej.DumpIPs, "User login;IP addresses\nbarmaley;10.0.0.7\naibolit;10.0.0.12 10.0.0.13\n"},
+	}
+	for _, tc := range tests {
+		r, err := tc.dump(csid)
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, string(data))
+	}
+}
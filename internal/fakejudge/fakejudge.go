@@ -0,0 +1,292 @@
+// Package fakejudge implements serve-control and new-master Ejudge pages
+// from recorded HTML fixtures for tests.
+package fakejudge
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/Gornak40/algolymp/ejudge"
+)
+
+const (
+	login    = "admin"
+	password = "admin"
+
+	masterSID = "ffe5d1c2b3a49788"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+type User struct {
+	Invisible  bool
+	Banned     bool
+	Locked     bool
+	Incomplete bool
+	Privileged bool
+}
+
+type Contest struct {
+	Visible    bool
+	Locked     bool
+	Created    int // template id
+	Reloaded   int
+	Registered []string
+	Users      map[int]*User
+	Runs       map[int]int // run id -> status
+	Comments   map[int][]string
+}
+
+type Server struct {
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	contests  map[int]*Contest
+	csids     map[string]int
+	committed int
+	loggedOut bool
+}
+
+// Starts a new server, it is closed automatically after the test.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		contests: make(map[int]*Contest),
+		csids:    make(map[string]int),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/serve-control", s.serveControl)
+	mux.HandleFunc("/new-master", s.newMaster)
+	s.srv = httptest.NewServer(mux)
+	t.Cleanup(s.srv.Close)
+
+	return s
+}
+
+func (s *Server) Config() *ejudge.Config {
+	return &ejudge.Config{
+		URL:      s.srv.URL,
+		Login:    login,
+		Password: password,
+	}
+}
+
+func (s *Server) Contest(cid int) *Contest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.contest(cid)
+}
+
+func (s *Server) Committed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.committed
+}
+
+func (s *Server) LoggedOut() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.loggedOut
+}
+
+func (s *Server) contest(cid int) *Contest {
+	c, ok := s.contests[cid]
+	if !ok {
+		c = &Contest{
+			Users:    make(map[int]*User),
+			Runs:     make(map[int]int),
+			Comments: make(map[int][]string),
+		}
+		s.contests[cid] = c
+	}
+
+	return c
+}
+
+func (c *Contest) user(uid int) *User {
+	u, ok := c.Users[uid]
+	if !ok {
+		u = new(User)
+		c.Users[uid] = u
+	}
+
+	return u
+}
+
+func writeFixture(w http.ResponseWriter, name string) {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+func formInt(r *http.Request, key string) int {
+	v, _ := strconv.Atoi(r.FormValue(key))
+
+	return v
+}
+
+//nolint:cyclop // ejudge actions
+func (s *Server) serveControl(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Method == http.MethodGet && r.FormValue("SID") == masterSID {
+		writeFixture(w, "serve-control.html")
+
+		return
+	}
+	if r.FormValue("login") != "" {
+		if r.FormValue("login") != login || r.FormValue("password") != password {
+			writeFixture(w, "serve-control-login.html")
+
+			return
+		}
+		http.Redirect(w, r, "/serve-control?SID="+masterSID, http.StatusFound)
+
+		return
+	}
+	if r.FormValue("SID") != masterSID {
+		writeFixture(w, "serve-control-login.html")
+
+		return
+	}
+	cid := formInt(r, "contest_id")
+	switch r.FormValue("action") {
+	case "6":
+		s.contest(cid).Visible = false
+	case "7":
+		s.contest(cid).Visible = true
+	case "55":
+		s.loggedOut = true
+		writeFixture(w, "serve-control-login.html")
+
+		return
+	case "259":
+		s.contest(cid).Created = formInt(r, "templ_id")
+		writeFixture(w, "create.html")
+
+		return
+	case "262":
+		writeFixture(w, "check.html")
+
+		return
+	case "276":
+		s.contest(cid).Locked = true
+	case "303":
+		s.committed++
+		writeFixture(w, "commit.html")
+
+		return
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+
+		return
+	}
+	writeFixture(w, "serve-control.html")
+}
+
+//nolint:cyclop,funlen // ejudge actions
+func (s *Server) newMaster(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sid := r.FormValue("SID")
+	if r.FormValue("action") == "3" {
+		if sid != masterSID {
+			http.Error(w, "permission denied", http.StatusForbidden)
+
+			return
+		}
+		cid := formInt(r, "contest_id")
+		csid := fmt.Sprintf("%016x", cid)
+		s.csids[csid] = cid
+		http.Redirect(w, r, "/new-master?SID="+csid, http.StatusFound)
+
+		return
+	}
+	cid, ok := s.csids[sid]
+	if !ok {
+		http.Error(w, "invalid session", http.StatusForbidden)
+
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeFixture(w, "new-master.html")
+
+		return
+	}
+	c := s.contest(cid)
+	if r.FormValue("filter_view") == "1" {
+		if r.FormValue("filter_expr") == "prob ==" {
+			writeFixture(w, "runs-error.html")
+		} else {
+			writeFixture(w, "runs.html")
+		}
+
+		return
+	}
+	uid := formInt(r, "user_id")
+	switch r.FormValue("action") {
+	case "20":
+		c.Registered = append(c.Registered, r.FormValue("add_login"))
+	case "62":
+		c.Reloaded++
+	case "64":
+		run := formInt(r, "run_id")
+		c.Comments[run] = append(c.Comments[run], r.FormValue("msg_text"))
+	case "67":
+		c.Runs[formInt(r, "run_id")] = formInt(r, "status")
+	case "121":
+		c.user(uid).Invisible = !c.user(uid).Invisible
+	case "122":
+		c.user(uid).Banned = !c.user(uid).Banned
+	case "123":
+		c.user(uid).Locked = !c.user(uid).Locked
+	case "124":
+		c.user(uid).Incomplete = !c.user(uid).Incomplete
+	case "289":
+		c.user(uid).Privileged = !c.user(uid).Privileged
+	case "94":
+		writeFixture(w, "standings.html")
+
+		return
+	case "120":
+		writeFixture(w, "passwords.html")
+
+		return
+	case "132":
+		w.Header().Set("Content-Type", "text/plain")
+		writeFixture(w, "users.csv")
+
+		return
+	case "152":
+		w.Header().Set("Content-Type", "text/plain")
+		writeFixture(w, "runs.csv")
+
+		return
+	case "235":
+		writeFixture(w, "ips.html")
+
+		return
+	case "309":
+		writeFixture(w, "problems.html")
+
+		return
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+
+		return
+	}
+	writeFixture(w, "ok.html")
+}
@@ -0,0 +1,455 @@
+// Package fakepolygon implements an in-process Polygon API server for tests.
+package fakepolygon
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gornak40/algolymp/polygon"
+)
+
+const (
+	apiKey    = "fake-key"
+	apiSecret = "fake-secret"
+
+	sigRandLen = 6
+	maxTimeLag = 5 * time.Minute
+	maxMemory  = 64 << 20
+)
+
+type File struct {
+	Type       polygon.FileType
+	Content    string
+	SourceType string
+}
+
+type Solution struct {
+	Content    string
+	Tag        polygon.SolutionTag
+	SourceType string
+}
+
+type Test struct {
+	Index           int
+	Manual          bool
+	Input           string
+	Group           string
+	Points          float32
+	Description     string
+	UseInStatements bool
+}
+
+type ValidatorTest struct {
+	Input   string
+	Verdict string
+}
+
+type CheckerTest struct {
+	Input   string
+	Output  string
+	Answer  string
+	Verdict string
+}
+
+type Problem struct {
+	ID          int
+	Name        string
+	Owner       string
+	Revision    int
+	Modified    bool
+	InputFile   string
+	OutputFile  string
+	Interactive bool
+	TimeLimit   int
+	MemoryLimit int
+
+	Tags       []string
+	Files      map[string]*File
+	Solutions  map[string]*Solution
+	Statements map[string]map[string]string // lang -> section -> text
+	Resources  map[string]string
+	Scripts    map[string]string
+
+	Checker    string
+	Validator  string
+	Interactor string
+
+	PointsEnabled bool
+	GroupsEnabled map[string]bool
+	Tests         map[string]map[int]*Test
+	Groups        map[string]map[string]*polygon.GroupAnswer
+
+	ValidatorTests map[int]*ValidatorTest
+	CheckerTests   map[int]*CheckerTest
+
+	Packages    []polygon.PackageAnswer
+	PackageData map[int][]byte
+	Commits     []string
+
+	// Built packages stay PENDING for BuildPolls problem.packages calls,
+	// then become FAILED if BuildFails is set, READY otherwise.
+	BuildPolls int
+	BuildFails bool
+	pending    map[int]int
+}
+
+type failure struct {
+	times  int
+	status int
+	body   string
+}
+
+type Server struct {
+	srv *httptest.Server
+	t   testing.TB
+
+	mu       sync.Mutex
+	problems map[int]*Problem
+	contests map[int]map[string]int
+	failures map[string]*failure
+	calls    []string
+}
+
+// Starts a new server, it is closed automatically after the test.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		t:        t,
+		problems: make(map[int]*Problem),
+		contests: make(map[int]map[string]int),
+		failures: make(map[string]*failure),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.srv.Close)
+
+	return s
+}
+
+func (s *Server) Config() *polygon.Config {
+	return &polygon.Config{
+		URL:       s.srv.URL,
+		APIKey:    apiKey,
+		APISecret: apiSecret,
+		RateLimit: 1000, //nolint:mnd // don't slow down tests
+	}
+}
+
+func (s *Server) AddProblem(id int, name string) *Problem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := &Problem{
+		ID:             id,
+		Name:           name,
+		Owner:          "fake",
+		Revision:       1,
+		InputFile:      "stdin",
+		OutputFile:     "stdout",
+		TimeLimit:      1000, //nolint:mnd // polygon default
+		MemoryLimit:    256,  //nolint:mnd // polygon default
+		Files:          make(map[string]*File),
+		Solutions:      make(map[string]*Solution),
+		Statements:     make(map[string]map[string]string),
+		Resources:      make(map[string]string),
+		Scripts:        make(map[string]string),
+		GroupsEnabled:  make(map[string]bool),
+		Tests:          make(map[string]map[int]*Test),
+		Groups:         make(map[string]map[string]*polygon.GroupAnswer),
+		ValidatorTests: make(map[int]*ValidatorTest),
+		CheckerTests:   make(map[int]*CheckerTest),
+		PackageData:    make(map[int][]byte),
+		pending:        make(map[int]int),
+	}
+	s.problems[id] = p
+
+	return p
+}
+
+// Adds a problem with points and groups enabled and a manual test per group,
+// first samples tests are used in statements.
+func (s *Server) AddScoredProblem(id int, name string, groups []string, points []float32, samples int) *Problem {
+	p := s.AddProblem(id, name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.PointsEnabled = true
+	p.GroupsEnabled[polygon.DefaultTestset] = true
+	for i, group := range groups {
+		t := p.test(polygon.DefaultTestset, i+1)
+		t.Manual = true
+		t.Input = "1 2"
+		t.Group = group
+		t.Points = points[i]
+		t.UseInStatements = i < samples
+		p.touchGroup(polygon.DefaultTestset, group)
+	}
+
+	return p
+}
+
+// Adds a ready package of the current revision, returns package id.
+func (s *Server) AddPackage(pID int, typ string, data []byte) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.problems[pID].addPackage(typ, data)
+}
+
+// Problem Idx (A, B, C) -> Problem ID.
+func (s *Server) AddContest(id int, problems map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contests[id] = problems
+}
+
+func (s *Server) Problem(id int) *Problem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.problems[id]
+}
+
+// Respond to the next n calls of the method with the raw body.
+func (s *Server) Fail(method string, n int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = &failure{times: n, status: http.StatusOK, body: body}
+}
+
+// Respond to the next n calls of the method with the HTTP error status.
+func (s *Server) FailStatus(method string, n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = &failure{times: n, status: status, body: http.StatusText(status)}
+}
+
+// Successfully called API methods in order.
+func (s *Server) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.calls...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/api/")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+	params, err := readParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f := s.failures[method]; f != nil && f.times > 0 {
+		f.times--
+		if f.status != http.StatusOK {
+			http.Error(w, f.body, f.status)
+
+			return
+		}
+		_, _ = io.WriteString(w, f.body)
+
+		return
+	}
+	if err := checkSignature(method, params); err != nil {
+		writeFailed(w, err.Error())
+
+		return
+	}
+	switch method {
+	case "problem.package", "problem.viewFile", "problem.viewSolution", "problem.script":
+		s.serveRaw(w, method, params)
+
+		return
+	}
+	res, err := s.call(method, params)
+	if err != nil {
+		writeFailed(w, err.Error())
+
+		return
+	}
+	s.calls = append(s.calls, method)
+	writeOK(w, res)
+}
+
+func readParams(r *http.Request) (url.Values, error) {
+	if r.Method == http.MethodGet {
+		return r.URL.Query(), nil
+	}
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	for k, vals := range r.MultipartForm.Value {
+		params[k] = append(params[k], vals...)
+	}
+	for k, files := range r.MultipartForm.File {
+		for _, fh := range files {
+			f, err := fh.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			params.Add(k, string(data))
+		}
+	}
+
+	return params, nil
+}
+
+func checkSignature(method string, params url.Values) error {
+	if params.Get("apiKey") != apiKey {
+		return fmt.Errorf("apiKey: Invalid api key")
+	}
+	ts, err := strconv.ParseInt(params.Get("time"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("time: %w", err)
+	}
+	if lag := time.Since(time.Unix(ts, 0)); lag > maxTimeLag || lag < -maxTimeLag {
+		return fmt.Errorf("time: Time is too far from current")
+	}
+	sig := params.Get("apiSig")
+	if len(sig) <= sigRandLen {
+		return fmt.Errorf("apiSig: Incorrect signature")
+	}
+	rnd := sig[:sigRandLen]
+
+	type pair struct{ key, value string }
+	var pairs []pair
+	for k, vals := range params {
+		if k == "apiSig" {
+			continue
+		}
+		for _, v := range vals {
+			pairs = append(pairs, pair{key: k, value: v})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+
+		return pairs[i].value < pairs[j].value
+	})
+	enc := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		enc = append(enc, p.key+"="+p.value)
+	}
+	b := sha512.Sum512([]byte(fmt.Sprintf("%s/%s?%s#%s", rnd, method, strings.Join(enc, "&"), apiSecret)))
+	if sig[sigRandLen:] != hex.EncodeToString(b[:]) {
+		return fmt.Errorf("apiSig: Incorrect signature")
+	}
+
+	return nil
+}
+
+func writeOK(w http.ResponseWriter, res any) {
+	data, _ := json.Marshal(res)
+	_ = json.NewEncoder(w).Encode(polygon.Answer{Status: "OK", Result: data})
+}
+
+func writeFailed(w http.ResponseWriter, comment string) {
+	_ = json.NewEncoder(w).Encode(polygon.Answer{Status: "FAILED", Comment: comment})
+}
+
+// Serves methods which respond with raw data on success.
+func (s *Server) serveRaw(w http.ResponseWriter, method string, params url.Values) {
+	prob, err := s.problem(params)
+	if err != nil {
+		writeFailed(w, err.Error())
+
+		return
+	}
+	data, err := prob.raw(method, params)
+	if err != nil {
+		writeFailed(w, err.Error())
+
+		return
+	}
+	s.calls = append(s.calls, method)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(data)
+}
+
+func (p *Problem) raw(method string, params url.Values) ([]byte, error) {
+	switch method {
+	case "problem.package":
+		packID, _ := strconv.Atoi(params.Get("packageId"))
+		data, ok := p.PackageData[packID]
+		if !ok {
+			return nil, fmt.Errorf("packageId: Package not found")
+		}
+
+		return data, nil
+	case "problem.viewFile":
+		f, ok := p.Files[params.Get("name")]
+		if !ok || f.Type != polygon.FileType(params.Get("type")) {
+			return nil, fmt.Errorf("name: File not found")
+		}
+
+		return []byte(f.Content), nil
+	case "problem.viewSolution":
+		sol, ok := p.Solutions[params.Get("name")]
+		if !ok {
+			return nil, fmt.Errorf("name: Solution not found")
+		}
+
+		return []byte(sol.Content), nil
+	default: // problem.script
+		return []byte(p.Scripts[testset(params)]), nil
+	}
+}
+
+func (s *Server) problem(params url.Values) (*Problem, error) {
+	id, err := strconv.Atoi(params.Get("problemId"))
+	if err != nil {
+		return nil, fmt.Errorf("problemId: %w", err)
+	}
+	prob, ok := s.problems[id]
+	if !ok {
+		return nil, fmt.Errorf("problemId: Problem not found")
+	}
+
+	return prob, nil
+}
+
+func testset(params url.Values) string {
+	if ts := params.Get("testset"); ts != "" {
+		return ts
+	}
+
+	return polygon.DefaultTestset
+}
+
+func packageZip(prob *Problem) []byte {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	w, _ := zw.Create("problem.xml")
+	_, _ = fmt.Fprintf(w, `<problem revision="%d" short-name="%s"></problem>`, prob.Revision, prob.Name)
+	_ = zw.Close()
+
+	return buf.Bytes()
+}
@@ -0,0 +1,555 @@
+package fakepolygon
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Gornak40/algolymp/polygon"
+)
+
+type testAnswer struct {
+	Index           int     `json:"index"`
+	Manual          bool    `json:"manual"`
+	Input           string  `json:"input,omitempty"`
+	Group           string  `json:"group,omitempty"`
+	Points          float32 `json:"points,omitempty"`
+	Description     string  `json:"description,omitempty"`
+	UseInStatements bool    `json:"useInStatements"`
+}
+
+//nolint:gochecknoglobals // script syntax
+var scriptTarget = regexp.MustCompile(`>\s*(?:\{(\d+)(?:-(\d+))?\}|(\d+))\s*$`)
+
+//nolint:gochecknoglobals // statement sections
+var statementSections = []string{
+	"name", "legend", "input", "output", "scoring",
+	"interaction", "notes", "tutorial",
+}
+
+//nolint:cyclop,funlen // API dispatcher
+func (s *Server) call(method string, params url.Values) (any, error) {
+	switch method {
+	case "problems.list":
+		return s.problemsList(params)
+	case "contest.problems":
+		return s.contestProblems(params)
+	}
+	prob, err := s.problem(params)
+	if err != nil {
+		return nil, err
+	}
+	ts := testset(params)
+	switch method {
+	case "problem.tests":
+		return prob.tests(ts), nil
+	case "problem.viewTestGroup":
+		return prob.groups(ts), nil
+	case "problem.packages":
+		prob.pollPackages()
+
+		return append([]polygon.PackageAnswer{}, prob.Packages...), nil
+	case "problem.info":
+		return prob.info(), nil
+	case "problem.checker":
+		return prob.Checker, nil
+	case "problem.validator":
+		return prob.Validator, nil
+	case "problem.interactor":
+		return prob.Interactor, nil
+	case "problem.viewTags":
+		return append([]string{}, prob.Tags...), nil
+	case "problem.files":
+		return prob.files(), nil
+	case "problem.solutions":
+		return prob.solutions(), nil
+	case "problem.statements":
+		return prob.statements(), nil
+	}
+
+	prob.Modified = true
+	switch method {
+	case "problem.buildPackage":
+		prob.buildPackage(params.Get("full") == "true")
+	case "problem.commitChanges":
+		prob.Revision++
+		prob.Modified = false
+		prob.Commits = append(prob.Commits, params.Get("message"))
+	case "problem.updateWorkingCopy":
+	case "problem.enableGroups":
+		prob.GroupsEnabled[ts] = params.Get("enable") == "true"
+	case "problem.enablePoints":
+		prob.PointsEnabled = params.Get("enable") == "true"
+	case "problem.setTestGroup":
+		return nil, prob.setTestGroup(ts, params)
+	case "problem.saveFile":
+		return nil, prob.saveFile(params)
+	case "problem.saveSolution":
+		return nil, prob.saveSolution(params)
+	case "problem.saveTest":
+		return nil, prob.saveTest(ts, params)
+	case "problem.saveTags":
+		prob.Tags = strings.Split(params.Get("tags"), ",")
+	case "problem.setChecker":
+		return nil, prob.setSource(&prob.Checker, "checker", params)
+	case "problem.setValidator":
+		return nil, prob.setSource(&prob.Validator, "validator", params)
+	case "problem.setInteractor":
+		return nil, prob.setSource(&prob.Interactor, "interactor", params)
+	case "problem.updateInfo":
+		return nil, prob.updateInfo(params)
+	case "problem.saveScript":
+		return nil, prob.saveScript(ts, params.Get("source"))
+	case "problem.saveStatement":
+		prob.saveStatement(params)
+	case "problem.saveStatementResource":
+		prob.Resources[params.Get("name")] = params.Get("file")
+	case "problem.saveValidatorTest":
+		idx, _ := strconv.Atoi(params.Get("testIndex"))
+		prob.ValidatorTests[idx] = &ValidatorTest{
+			Input: params.Get("testInput"), Verdict: params.Get("testVerdict"),
+		}
+	case "problem.saveCheckerTest":
+		idx, _ := strconv.Atoi(params.Get("testIndex"))
+		prob.CheckerTests[idx] = &CheckerTest{
+			Input: params.Get("testInput"), Output: params.Get("testOutput"),
+			Answer: params.Get("testAnswer"), Verdict: params.Get("testVerdict"),
+		}
+	case "problem.saveTestGroup":
+		return nil, prob.saveTestGroup(ts, params)
+	default:
+		return nil, fmt.Errorf("method: Unknown method %s", method)
+	}
+
+	return nil, nil //nolint:nilnil // empty result
+}
+
+func (s *Server) problemsList(params url.Values) ([]polygon.ProblemAnswer, error) {
+	res := []polygon.ProblemAnswer{}
+	id, _ := strconv.Atoi(params.Get("id"))
+	for _, prob := range s.problems {
+		if id != 0 && prob.ID != id {
+			continue
+		}
+		res = append(res, prob.answer())
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+
+	return res, nil
+}
+
+func (s *Server) contestProblems(params url.Values) (map[string]polygon.ProblemAnswer, error) {
+	cID, _ := strconv.Atoi(params.Get("contestId"))
+	contest, ok := s.contests[cID]
+	if !ok {
+		return nil, fmt.Errorf("contestId: Contest not found")
+	}
+	res := make(map[string]polygon.ProblemAnswer, len(contest))
+	for idx, pID := range contest {
+		if prob, ok := s.problems[pID]; ok {
+			res[idx] = prob.answer()
+		}
+	}
+
+	return res, nil
+}
+
+func (p *Problem) answer() polygon.ProblemAnswer {
+	latest := 0
+	if len(p.Packages) != 0 {
+		latest = p.Packages[len(p.Packages)-1].ID
+	}
+
+	return polygon.ProblemAnswer{
+		ID:            p.ID,
+		Owner:         p.Owner,
+		Name:          p.Name,
+		AccessType:    "OWNER",
+		Revision:      p.Revision,
+		LatestPackage: latest,
+		Modified:      p.Modified,
+	}
+}
+
+func (p *Problem) info() polygon.InfoAnswer {
+	return polygon.InfoAnswer{
+		InputFile:   p.InputFile,
+		OutputFile:  p.OutputFile,
+		Interactive: p.Interactive,
+		TimeLimit:   p.TimeLimit,
+		MemoryLimit: p.MemoryLimit,
+	}
+}
+
+func (p *Problem) files() polygon.FilesAnswer {
+	res := polygon.FilesAnswer{
+		ResourceFiles: []polygon.FileAnswer{},
+		SourceFiles:   []polygon.FileAnswer{},
+		AuxFiles:      []polygon.FileAnswer{},
+	}
+	for name, f := range p.Files {
+		ans := polygon.FileAnswer{Name: name, Length: len(f.Content), SourceType: f.SourceType}
+		switch f.Type {
+		case polygon.TypeResource:
+			res.ResourceFiles = append(res.ResourceFiles, ans)
+		case polygon.TypeSource:
+			res.SourceFiles = append(res.SourceFiles, ans)
+		case polygon.TypeAUX:
+			res.AuxFiles = append(res.AuxFiles, ans)
+		}
+	}
+	for _, list := range [][]polygon.FileAnswer{res.ResourceFiles, res.SourceFiles, res.AuxFiles} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	}
+
+	return res
+}
+
+func (p *Problem) solutions() []polygon.SolutionAnswer {
+	res := make([]polygon.SolutionAnswer, 0, len(p.Solutions))
+	for name, sol := range p.Solutions {
+		res = append(res, polygon.SolutionAnswer{
+			Name:       name,
+			Length:     len(sol.Content),
+			SourceType: sol.SourceType,
+			Tag:        sol.Tag,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	return res
+}
+
+func (p *Problem) statements() map[string]polygon.StatementAnswer {
+	res := make(map[string]polygon.StatementAnswer, len(p.Statements))
+	for lang, secs := range p.Statements {
+		res[lang] = polygon.StatementAnswer{
+			Encoding:    "UTF-8",
+			Name:        secs["name"],
+			Legend:      secs["legend"],
+			Input:       secs["input"],
+			Output:      secs["output"],
+			Scoring:     secs["scoring"],
+			Interaction: secs["interaction"],
+			Notes:       secs["notes"],
+			Tutorial:    secs["tutorial"],
+		}
+	}
+
+	return res
+}
+
+func (p *Problem) tests(ts string) []testAnswer {
+	res := make([]testAnswer, 0, len(p.Tests[ts]))
+	for _, t := range p.Tests[ts] {
+		ans := testAnswer{
+			Index:           t.Index,
+			Manual:          t.Manual,
+			Group:           t.Group,
+			Points:          t.Points,
+			Description:     t.Description,
+			UseInStatements: t.UseInStatements,
+		}
+		if t.Manual {
+			ans.Input = t.Input
+		}
+		res = append(res, ans)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Index < res[j].Index })
+
+	return res
+}
+
+func (p *Problem) groups(ts string) []polygon.GroupAnswer {
+	res := make([]polygon.GroupAnswer, 0, len(p.Groups[ts]))
+	for _, g := range p.Groups[ts] {
+		res = append(res, *g)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	return res
+}
+
+// Adds a group to the testset on first usage, like Polygon does.
+func (p *Problem) touchGroup(ts, name string) {
+	if p.Groups[ts] == nil {
+		p.Groups[ts] = make(map[string]*polygon.GroupAnswer)
+	}
+	if _, ok := p.Groups[ts][name]; !ok {
+		p.Groups[ts][name] = &polygon.GroupAnswer{
+			Name:           name,
+			PointsPolicy:   polygon.PolicyCompleteGroup,
+			FeedbackPolicy: polygon.PolicyICPC,
+			Dependencies:   []string{},
+		}
+	}
+}
+
+func (p *Problem) test(ts string, idx int) *Test {
+	if p.Tests[ts] == nil {
+		p.Tests[ts] = make(map[int]*Test)
+	}
+	t, ok := p.Tests[ts][idx]
+	if !ok {
+		t = &Test{Index: idx}
+		p.Tests[ts][idx] = t
+	}
+
+	return t
+}
+
+func (p *Problem) buildPackage(full bool) {
+	typ := "standard"
+	if full {
+		typ = "linux"
+	}
+	id := p.addPackage(typ, packageZip(p))
+	switch {
+	case p.BuildPolls > 0:
+		p.Packages[id-1].State = "PENDING"
+		p.pending[id] = p.BuildPolls
+	case p.BuildFails:
+		p.Packages[id-1].State = "FAILED"
+	}
+}
+
+func (p *Problem) pollPackages() {
+	for id, polls := range p.pending {
+		if polls > 1 {
+			p.pending[id]--
+
+			continue
+		}
+		delete(p.pending, id)
+		p.Packages[id-1].State = "READY"
+		if p.BuildFails {
+			p.Packages[id-1].State = "FAILED"
+		}
+	}
+}
+
+func (p *Problem) addPackage(typ string, data []byte) int {
+	id := len(p.Packages) + 1
+	p.Packages = append(p.Packages, polygon.PackageAnswer{
+		ID:       id,
+		Revision: p.Revision,
+		State:    "READY",
+		Type:     typ,
+	})
+	p.PackageData[id] = data
+
+	return id
+}
+
+func (p *Problem) setTestGroup(ts string, params url.Values) error {
+	if !p.GroupsEnabled[ts] {
+		return fmt.Errorf("testGroup: Groups are disabled for the testset")
+	}
+	group := params.Get("testGroup")
+	for _, si := range strings.Split(params.Get("testIndices"), ",") {
+		idx, err := strconv.Atoi(si)
+		if err != nil {
+			return fmt.Errorf("testIndices: %w", err)
+		}
+		t, ok := p.Tests[ts][idx]
+		if !ok {
+			return fmt.Errorf("testIndices: Test %d not found", idx)
+		}
+		t.Group = group
+	}
+	p.touchGroup(ts, group)
+
+	return nil
+}
+
+func (p *Problem) saveFile(params url.Values) error {
+	name := params.Get("name")
+	if _, ok := p.Files[name]; ok && params.Get("checkExisting") == "true" {
+		return fmt.Errorf("name: File with such name already exists")
+	}
+	p.Files[name] = &File{
+		Type:       polygon.FileType(params.Get("type")),
+		Content:    params.Get("file"),
+		SourceType: params.Get("sourceType"),
+	}
+
+	return nil
+}
+
+func (p *Problem) saveSolution(params url.Values) error {
+	name := params.Get("name")
+	if _, ok := p.Solutions[name]; ok && params.Get("checkExisting") == "true" {
+		return fmt.Errorf("name: Solution with such name already exists")
+	}
+	p.Solutions[name] = &Solution{
+		Content:    params.Get("file"),
+		Tag:        polygon.SolutionTag(params.Get("tag")),
+		SourceType: params.Get("sourceType"),
+	}
+
+	return nil
+}
+
+//nolint:cyclop // a lot of optional parameters
+func (p *Problem) saveTest(ts string, params url.Values) error {
+	idx, err := strconv.Atoi(params.Get("testIndex"))
+	if err != nil {
+		return fmt.Errorf("testIndex: %w", err)
+	}
+	_, exists := p.Tests[ts][idx]
+	if exists && params.Get("checkExisting") == "true" {
+		return fmt.Errorf("testIndex: Test with index %d already exists", idx)
+	}
+	if !exists && !params.Has("testInput") {
+		return fmt.Errorf("testInput: Input is required for a new test")
+	}
+	if params.Has("testGroup") && !p.GroupsEnabled[ts] {
+		return fmt.Errorf("testGroup: Groups are disabled for the testset")
+	}
+	if params.Has("testPoints") && !p.PointsEnabled {
+		return fmt.Errorf("testPoints: Points are disabled for the problem")
+	}
+	t := p.test(ts, idx)
+	if params.Has("testInput") {
+		t.Manual = true
+		t.Input = params.Get("testInput")
+	}
+	if params.Has("testGroup") {
+		t.Group = params.Get("testGroup")
+		p.touchGroup(ts, t.Group)
+	}
+	if params.Has("testPoints") {
+		points, err := strconv.ParseFloat(params.Get("testPoints"), 32)
+		if err != nil {
+			return fmt.Errorf("testPoints: %w", err)
+		}
+		t.Points = float32(points)
+	}
+	if params.Has("testDescription") {
+		t.Description = params.Get("testDescription")
+	}
+	if params.Has("testUseInStatements") {
+		t.UseInStatements = params.Get("testUseInStatements") == "true"
+	}
+
+	return nil
+}
+
+func (p *Problem) setSource(dst *string, param string, params url.Values) error {
+	name := params.Get(param)
+	if param == "checker" && strings.HasPrefix(name, "std::") {
+		*dst = name
+
+		return nil
+	}
+	if f, ok := p.Files[name]; !ok || f.Type != polygon.TypeSource {
+		return fmt.Errorf("%s: Source file %s not found", param, name)
+	}
+	*dst = name
+
+	return nil
+}
+
+func (p *Problem) updateInfo(params url.Values) error {
+	if params.Has("inputFile") {
+		p.InputFile = params.Get("inputFile")
+	}
+	if params.Has("outputFile") {
+		p.OutputFile = params.Get("outputFile")
+	}
+	if params.Has("interactive") {
+		p.Interactive = params.Get("interactive") == "true"
+	}
+	for _, f := range []struct {
+		name string
+		dst  *int
+	}{{"timeLimit", &p.TimeLimit}, {"memoryLimit", &p.MemoryLimit}} {
+		if !params.Has(f.name) {
+			continue
+		}
+		v, err := strconv.Atoi(params.Get(f.name))
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		*f.dst = v
+	}
+
+	return nil
+}
+
+// Creates generated tests for `gen > 1`, `gen > {2}` and `gen > {3-10}` lines.
+func (p *Problem) saveScript(ts, source string) error {
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		m := scriptTarget.FindStringSubmatch(line)
+		if m == nil {
+			return fmt.Errorf("source: Can't parse script line %q", line)
+		}
+		var first, last int
+		switch {
+		case m[3] != "":
+			first, _ = strconv.Atoi(m[3])
+			last = first
+		case m[2] != "":
+			first, _ = strconv.Atoi(m[1])
+			last, _ = strconv.Atoi(m[2])
+		default:
+			first, _ = strconv.Atoi(m[1])
+			last = first
+		}
+		for idx := first; idx <= last; idx++ {
+			if t, ok := p.Tests[ts][idx]; ok && t.Manual {
+				return fmt.Errorf("source: Test %d is already manual", idx)
+			}
+			p.test(ts, idx)
+		}
+	}
+	p.Scripts[ts] = source
+
+	return nil
+}
+
+func (p *Problem) saveStatement(params url.Values) {
+	lang := params.Get("lang")
+	if p.Statements[lang] == nil {
+		p.Statements[lang] = make(map[string]string)
+	}
+	for _, sec := range statementSections {
+		if params.Has(sec) {
+			p.Statements[lang][sec] = params.Get(sec)
+		}
+	}
+}
+
+func (p *Problem) saveTestGroup(ts string, params url.Values) error {
+	if !p.GroupsEnabled[ts] {
+		return fmt.Errorf("group: Groups are disabled for the testset")
+	}
+	name := params.Get("group")
+	g, ok := p.Groups[ts][name]
+	if !ok {
+		return fmt.Errorf("group: Group %s not found", name)
+	}
+	if params.Has("pointsPolicy") {
+		g.PointsPolicy = params.Get("pointsPolicy")
+	}
+	if params.Has("feedbackPolicy") {
+		g.FeedbackPolicy = params.Get("feedbackPolicy")
+	}
+	if params.Has("dependencies") {
+		g.Dependencies = []string{}
+		for _, d := range strings.Split(params.Get("dependencies"), ",") {
+			if d != "" {
+				g.Dependencies = append(g.Dependencies, d)
+			}
+		}
+	}
+
+	return nil
+}
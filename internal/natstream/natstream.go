@@ -2,42 +2,70 @@ package natstream
 
 import (
 	"errors"
-	"os"
-	"path/filepath"
+	"fmt"
+	"io/fs"
+	"regexp"
 
 	"github.com/facette/natsort"
 )
 
 var (
-	ErrEndStream = errors.New("no more files in natstream")
+	ErrBadIndex    = errors.New("bad file index")
+	ErrMissingFile = errors.New("missing file")
 )
 
+//nolint:gochecknoglobals // printf index verb
+var reIndexVerb = regexp.MustCompile(`%0?\d*d`)
+
 type NatStream struct {
-	files []string
-	idx   int
+	fsys    fs.FS
+	pattern string
+	files   []string
 }
 
-func (ns *NatStream) Init(glob string) error {
-	files, err := filepath.Glob(glob)
-	if err != nil {
-		return err
+// Files are resolved by 1-based index from printf-style pattern, e.g. tests/%02d.
+// Missing files are kept, so the index always matches the file.
+func (ns *NatStream) InitPattern(fsys fs.FS, pattern string, count int) {
+	files := make([]string, 0, count)
+	for idx := 1; idx <= count; idx++ {
+		files = append(files, fmt.Sprintf(pattern, idx))
 	}
-	ns.files = files
-	natsort.Sort(ns.files)
-	ns.idx = 0
-
-	return nil
+	ns.fsys = fsys
+	ns.pattern = pattern
+	ns.files = files // keep the test order
 }
 
-func (ns *NatStream) Next() (string, error) {
-	if ns.idx == len(ns.files) {
-		return "", ErrEndStream
+// Path of the existing file by 1-based index.
+func (ns *NatStream) Path(idx int) (string, error) {
+	if idx < 1 || idx > len(ns.files) {
+		return "", fmt.Errorf("%w: %d", ErrBadIndex, idx)
+	}
+	path := ns.files[idx-1]
+	if _, err := fs.Stat(ns.fsys, path); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrMissingFile, path)
 	}
-	data, err := os.ReadFile(ns.files[ns.idx])
+
+	return path, nil
+}
+
+// Files matching the pattern with index out of the stream, in natural order.
+func (ns *NatStream) Unused() ([]string, error) {
+	matches, err := fs.Glob(ns.fsys, reIndexVerb.ReplaceAllString(ns.pattern, "*"))
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	scan := reIndexVerb.ReplaceAllString(ns.pattern, "%d") // no width limit, e.g. tests/100
+	var unused []string
+	for _, file := range matches {
+		var idx int
+		if _, err := fmt.Sscanf(file, scan, &idx); err != nil || fmt.Sprintf(ns.pattern, idx) != file {
+			continue // other file in the same directory, e.g. answer
+		}
+		if idx < 1 || idx > len(ns.files) {
+			unused = append(unused, file)
+		}
 	}
-	ns.idx++
+	natsort.Sort(unused)
 
-	return string(data), nil
+	return unused, nil
 }
@@ -4,6 +4,8 @@ import "encoding/json"
 
 type TestAnswer struct {
 	Index           int     `json:"index"`
+	Manual          bool    `json:"manual"`
+	Input           string  `json:"input"`
 	Group           string  `json:"group"`
 	Points          float32 `json:"points"`
 	UseInStatements bool    `json:"useInStatements"`
@@ -37,6 +39,47 @@ type ProblemAnswer struct {
 	Modified      bool   `json:"modified"`
 }
 
+type FileAnswer struct {
+	Name                    string `json:"name"`
+	ModificationTimeSeconds int    `json:"modificationTimeSeconds"`
+	Length                  int    `json:"length"`
+	SourceType              string `json:"sourceType,omitempty"`
+}
+
+type FilesAnswer struct {
+	ResourceFiles []FileAnswer `json:"resourceFiles"`
+	SourceFiles   []FileAnswer `json:"sourceFiles"`
+	AuxFiles      []FileAnswer `json:"auxFiles"`
+}
+
+type SolutionAnswer struct {
+	Name                    string      `json:"name"`
+	ModificationTimeSeconds int         `json:"modificationTimeSeconds"`
+	Length                  int         `json:"length"`
+	SourceType              string      `json:"sourceType"`
+	Tag                     SolutionTag `json:"tag"`
+}
+
+type StatementAnswer struct {
+	Encoding    string `json:"encoding"`
+	Name        string `json:"name"`
+	Legend      string `json:"legend"`
+	Input       string `json:"input"`
+	Output      string `json:"output"`
+	Scoring     string `json:"scoring"`
+	Interaction string `json:"interaction"`
+	Notes       string `json:"notes"`
+	Tutorial    string `json:"tutorial"`
+}
+
+type InfoAnswer struct {
+	InputFile   string `json:"inputFile"`
+	OutputFile  string `json:"outputFile"`
+	Interactive bool   `json:"interactive"`
+	TimeLimit   int    `json:"timeLimit"`
+	MemoryLimit int    `json:"memoryLimit"`
+}
+
 type Answer struct {
 	Status  string          `json:"status"`
 	Comment string          `json:"comment"`
@@ -12,6 +12,8 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"path"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -48,21 +50,38 @@ const (
 	TypeAUX      FileType = "aux"
 )
 
+const (
+	PackageStandard = "standard"
+	PackageLinux    = "linux"
+	PackageWindows  = "windows"
+
+	PackageReady  = "READY"
+	PackageFailed = "FAILED"
+)
+
 var (
 	ErrBadPolygonStatus = errors.New("bad polygon status")
+	ErrBadResponse      = errors.New("bad polygon response")
+	ErrTooManyAttempts  = errors.New("too many attempts")
 	ErrInvalidMethod    = errors.New("invalid method")
 	ErrProblemNotFound  = errors.New("problem not found")
+	ErrNoPackage        = errors.New("no suitable package")
 )
 
 type Config struct {
-	URL       string `json:"url"`
-	APIKey    string `json:"apiKey"`
-	APISecret string `json:"apiSecret"`
+	URL         string  `json:"url"`
+	APIKey      string  `json:"apiKey"`
+	APISecret   string  `json:"apiSecret"`
+	MaxAttempts int     `json:"maxAttempts,omitempty"`
+	RateLimit   float64 `json:"rateLimit,omitempty"` // requests per second
+	Legacy      bool    `json:"legacy,omitempty"`    // polygon.lksh.ru languages
 }
 
 type Polygon struct {
-	cfg    *Config
-	client *http.Client
+	cfg         *Config
+	client      *http.Client
+	limiter     *limiter
+	maxAttempts int
 }
 
 func NewPolygon(cfg *Config) *Polygon {
@@ -71,9 +90,20 @@ func NewPolygon(cfg *Config) *Polygon {
 	client := retryablehttp.NewClient()
 	client.Logger = nil
 
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	rateLimit := cfg.RateLimit
+	if rateLimit <= 0 {
+		rateLimit = defaultRateLimit
+	}
+
 	return &Polygon{
-		cfg:    cfg,
-		client: client.StandardClient(),
+		cfg:         cfg,
+		client:      client.StandardClient(),
+		limiter:     newLimiter(rateLimit),
+		maxAttempts: maxAttempts,
 	}
 }
 
@@ -181,10 +211,28 @@ func (p *Polygon) GetPackages(pID int) ([]PackageAnswer, error) {
 	return packages, nil
 }
 
-func (p *Polygon) GetGroups(pID int) ([]GroupAnswer, error) {
+// Find a ready package of the revision.
+// Full packages (linux or windows) can be downloaded as any type.
+func (p *Polygon) FindPackage(pID, revision int, packType string) (*PackageAnswer, error) {
+	pkgs, err := p.GetPackages(pID)
+	if err != nil {
+		return nil, err
+	}
+	idx := slices.IndexFunc(pkgs, func(p PackageAnswer) bool {
+		return p.State == PackageReady && p.Revision == revision &&
+			(p.Type == packType || p.Type != PackageStandard)
+	})
+	if idx == -1 {
+		return nil, ErrNoPackage
+	}
+
+	return &pkgs[idx], nil
+}
+
+func (p *Polygon) GetGroups(pID int, testset string) ([]GroupAnswer, error) {
 	link, params := p.buildURL("problem.viewTestGroup", url.Values{
 		"problemId": {strconv.Itoa(pID)},
-		"testset":   {DefaultTestset},
+		"testset":   {testset},
 	})
 	ansG, err := p.makeQuery(http.MethodGet, link, params)
 	if err != nil {
@@ -217,10 +265,10 @@ func (p *Polygon) GetProblem(pID int) (*ProblemAnswer, error) {
 	return &problems[0], nil
 }
 
-func (p *Polygon) GetTests(pID int) ([]TestAnswer, error) {
+func (p *Polygon) GetTests(pID int, testset string) ([]TestAnswer, error) {
 	link, params := p.buildURL("problem.tests", url.Values{
 		"problemId": {strconv.Itoa(pID)},
-		"testset":   {DefaultTestset},
+		"testset":   {testset},
 		// "noInputs":  {"true"}, // https://github.com/Codeforces/polygon-issue-tracking/issues/565
 	})
 	ansT, err := p.makeQuery(http.MethodGet, link, params)
@@ -235,29 +283,160 @@ func (p *Polygon) GetTests(pID int) ([]TestAnswer, error) {
 	return tests, nil
 }
 
-func (p *Polygon) DownloadPackage(pID, packID int, packType string) ([]byte, error) {
-	link, params := p.buildURL("problem.package", url.Values{
+func (p *Polygon) GetInfo(pID int) (*InfoAnswer, error) {
+	link, params := p.buildURL("problem.info", url.Values{
 		"problemId": {strconv.Itoa(pID)},
-		"packageId": {strconv.Itoa(packID)},
-		"type":      {packType},
 	})
-	req, err := buildRequest(http.MethodPost, link, params)
+	ansI, err := p.makeQuery(http.MethodGet, link, params)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := p.client.Do(req)
+	var info InfoAnswer
+	if err := json.Unmarshal(ansI.Result, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+func (p *Polygon) GetTags(pID int) ([]string, error) {
+	link, params := p.buildURL("problem.viewTags", url.Values{
+		"problemId": {strconv.Itoa(pID)},
+	})
+	ansT, err := p.makeQuery(http.MethodGet, link, params)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	var tags []string
+	if err := json.Unmarshal(ansT.Result, &tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (p *Polygon) GetChecker(pID int) (string, error) {
+	return p.getSourceName("problem.checker", pID)
+}
 
-	return io.ReadAll(resp.Body)
+func (p *Polygon) GetValidator(pID int) (string, error) {
+	return p.getSourceName("problem.validator", pID)
 }
 
-func (p *Polygon) EnableGroups(pID int) error {
+func (p *Polygon) GetInteractor(pID int) (string, error) {
+	return p.getSourceName("problem.interactor", pID)
+}
+
+func (p *Polygon) getSourceName(method string, pID int) (string, error) {
+	link, params := p.buildURL(method, url.Values{
+		"problemId": {strconv.Itoa(pID)},
+	})
+	ansS, err := p.makeQuery(http.MethodGet, link, params)
+	if err != nil {
+		return "", err
+	}
+	var name string
+	if err := json.Unmarshal(ansS.Result, &name); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+func (p *Polygon) GetFiles(pID int) (*FilesAnswer, error) {
+	link, params := p.buildURL("problem.files", url.Values{
+		"problemId": {strconv.Itoa(pID)},
+	})
+	ansF, err := p.makeQuery(http.MethodGet, link, params)
+	if err != nil {
+		return nil, err
+	}
+	var files FilesAnswer
+	if err := json.Unmarshal(ansF.Result, &files); err != nil {
+		return nil, err
+	}
+
+	return &files, nil
+}
+
+func (p *Polygon) ViewFile(pID int, typ FileType, name string) (string, error) {
+	link, params := p.buildURL("problem.viewFile", url.Values{
+		"problemId": {strconv.Itoa(pID)},
+		"type":      {string(typ)},
+		"name":      {name},
+	})
+	data, err := p.rawQuery(link, params)
+
+	return string(data), err
+}
+
+func (p *Polygon) GetSolutions(pID int) ([]SolutionAnswer, error) {
+	link, params := p.buildURL("problem.solutions", url.Values{
+		"problemId": {strconv.Itoa(pID)},
+	})
+	ansS, err := p.makeQuery(http.MethodGet, link, params)
+	if err != nil {
+		return nil, err
+	}
+	var solutions []SolutionAnswer
+	if err := json.Unmarshal(ansS.Result, &solutions); err != nil {
+		return nil, err
+	}
+
+	return solutions, nil
+}
+
+func (p *Polygon) ViewSolution(pID int, name string) (string, error) {
+	link, params := p.buildURL("problem.viewSolution", url.Values{
+		"problemId": {strconv.Itoa(pID)},
+		"name":      {name},
+	})
+	data, err := p.rawQuery(link, params)
+
+	return string(data), err
+}
+
+// Language -> statement.
+func (p *Polygon) GetStatements(pID int) (map[string]StatementAnswer, error) {
+	link, params := p.buildURL("problem.statements", url.Values{
+		"problemId": {strconv.Itoa(pID)},
+	})
+	ansS, err := p.makeQuery(http.MethodGet, link, params)
+	if err != nil {
+		return nil, err
+	}
+	var statements map[string]StatementAnswer
+	if err := json.Unmarshal(ansS.Result, &statements); err != nil {
+		return nil, err
+	}
+
+	return statements, nil
+}
+
+func (p *Polygon) GetScript(pID int, testset string) (string, error) {
+	link, params := p.buildURL("problem.script", url.Values{
+		"problemId": {strconv.Itoa(pID)},
+		"testset":   {testset},
+	})
+	data, err := p.rawQuery(link, params)
+
+	return string(data), err
+}
+
+func (p *Polygon) DownloadPackage(pID, packID int, packType string) ([]byte, error) {
+	link, params := p.buildURL("problem.package", url.Values{
+		"problemId": {strconv.Itoa(pID)},
+		"packageId": {strconv.Itoa(packID)},
+		"type":      {packType},
+	})
+
+	return p.rawQuery(link, params)
+}
+
+func (p *Polygon) EnableGroups(pID int, testset string) error {
 	link, params := p.buildURL("problem.enableGroups", url.Values{
 		"problemId": {strconv.Itoa(pID)},
-		"testset":   {DefaultTestset},
+		"testset":   {testset},
 		"enable":    {"true"},
 	})
 	_, err := p.makeQuery(http.MethodPost, link, params)
@@ -275,14 +454,14 @@ func (p *Polygon) EnablePoints(pID int) error {
 	return err
 }
 
-func (p *Polygon) SetTestGroup(pID int, group string, tests []int) error {
+func (p *Polygon) SetTestGroup(pID int, testset, group string, tests []int) error {
 	st := make([]string, 0, len(tests))
 	for _, v := range tests {
 		st = append(st, strconv.Itoa(v))
 	}
 	link, params := p.buildURL("problem.setTestGroup", url.Values{
 		"problemId":   {strconv.Itoa(pID)},
-		"testset":     {DefaultTestset},
+		"testset":     {testset},
 		"testGroup":   {group},
 		"testIndices": {strings.Join(st, ",")},
 	})
@@ -410,29 +589,119 @@ func (p *Polygon) SaveStatementResource(pID int, name, data string) error {
 }
 
 func (p *Polygon) makeQuery(method, link string, params url.Values) (*Answer, error) {
-	req, err := buildRequest(method, link, params)
-	if err != nil {
-		return nil, err
+	var ans *Answer
+	err := p.retry(link, func() (bool, error) {
+		var retry bool
+		var err error
+		ans, retry, err = p.doQuery(method, link, params)
+
+		return retry, err
+	})
+
+	return ans, err
+}
+
+// Methods like problem.viewFile respond with raw data on success and JSON on failure.
+func (p *Polygon) rawQuery(link string, params url.Values) ([]byte, error) {
+	var data []byte
+	err := p.retry(link, func() (bool, error) {
+		var retry bool
+		var err error
+		data, retry, err = p.doRawQuery(link, params)
+
+		return retry, err
+	})
+
+	return data, err
+}
+
+// Repeats the query while it fails with a retryable error.
+func (p *Polygon) retry(link string, query func() (bool, error)) error {
+	var lastErr error
+	for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+		if attempt > 1 {
+			delay := backoff(attempt - 1)
+			logrus.WithError(lastErr).WithFields(logrus.Fields{
+				"url": link, "attempt": attempt, "delay": delay,
+			}).Warn("retry request")
+			time.Sleep(delay)
+		}
+		retry, err := query()
+		if err == nil {
+			if attempt > 1 {
+				logrus.WithFields(logrus.Fields{"url": link, "attempts": attempt}).
+					Info("request succeeded after retries")
+			}
+
+			return nil
+		}
+		if !retry {
+			return err
+		}
+		lastErr = err
 	}
+
+	return fmt.Errorf("%w: %d attempts: %w", ErrTooManyAttempts, p.maxAttempts, lastErr)
+}
+
+// Response is nil if the request failed, otherwise the body is worth reading again.
+func (p *Polygon) send(req *http.Request) (*http.Response, []byte, error) {
+	p.limiter.wait()
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 	data, err := io.ReadAll(resp.Body)
+
+	return resp, data, err
+}
+
+// Returns true if the request is worth retrying.
+func (p *Polygon) doQuery(method, link string, params url.Values) (*Answer, bool, error) {
+	req, err := buildRequest(method, link, params)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	resp, data, err := p.send(req)
+	if err != nil {
+		return nil, resp != nil, err
 	}
 	var ans Answer
 	if err := json.Unmarshal(data, &ans); err != nil {
-		// TODO: add retry with exponential backoff.
-		return nil, err
+		// Polygon sometimes responds with HTML error pages.
+		return nil, true, newResponseError(path.Base(link), params, resp, data)
 	}
 	if ans.Status != "OK" {
-		return nil, fmt.Errorf("%w: %s", ErrBadPolygonStatus, ans.Comment)
+		apiErr := newAPIError(path.Base(link), params, ans.Comment)
+
+		return nil, apiErr.Kind.Temporary(), apiErr
+	}
+
+	return &ans, false, nil
+}
+
+// Same as doQuery, but the data is returned as is.
+func (p *Polygon) doRawQuery(link string, params url.Values) ([]byte, bool, error) {
+	req, err := buildRequest(http.MethodPost, link, params)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, data, err := p.send(req)
+	if err != nil {
+		return nil, resp != nil, err
+	}
+	var ans Answer
+	if json.Unmarshal(data, &ans) == nil && ans.Status == "FAILED" {
+		apiErr := newAPIError(path.Base(link), params, ans.Comment)
+
+		return nil, apiErr.Kind.Temporary(), apiErr
+	}
+	if isErrorPage(resp) {
+		return nil, true, newResponseError(path.Base(link), params, resp, data)
 	}
 
-	return &ans, nil
+	return data, false, nil
 }
 
 func (p *Polygon) skipEscape(params url.Values) string {
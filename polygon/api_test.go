@@ -0,0 +1,172 @@
+package polygon_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+
+	prob, err := pc.GetProblem(42)
+	require.NoError(t, err)
+	require.Equal(t, "aplusb", prob.Name)
+	require.Equal(t, 1, prob.Revision)
+
+	_, err = pc.GetProblem(43)
+	require.ErrorIs(t, err, polygon.ErrProblemNotFound)
+
+	cfg := srv.Config()
+	cfg.APISecret = "wrong"
+	_, err = polygon.NewPolygon(cfg).GetProblem(42)
+	require.ErrorIs(t, err, polygon.ErrBadPolygonStatus)
+}
+
+func TestPostParams(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+
+	input := "1 2\r\n& = ? # %\n"
+	require.NoError(t, pc.SaveTest(polygon.NewTestRequest(42, 1).Input(input).UseInStatements(true)))
+	require.Equal(t, input, prob.Tests[polygon.DefaultTestset][1].Input)
+
+	err := pc.SaveTest(polygon.NewTestRequest(42, 2).Points(5))
+	require.ErrorIs(t, err, polygon.ErrBadPolygonStatus)
+}
+
+func TestRetryBadResponse(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	srv.Fail("problems.list", 2, "<html>502 Bad Gateway</html>")
+	pc := polygon.NewPolygon(srv.Config())
+
+	prob, err := pc.GetProblem(42)
+	require.NoError(t, err)
+	require.Equal(t, 42, prob.ID)
+}
+
+func TestRetryThrottled(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	srv.Fail("problem.commitChanges", 1, `{"status":"FAILED","comment":"Too many requests, try again later"}`)
+	pc := polygon.NewPolygon(srv.Config())
+
+	require.NoError(t, pc.Commit(42, true, "retry"))
+	require.Equal(t, []string{"retry"}, srv.Problem(42).Commits)
+}
+
+func TestTooManyAttempts(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	srv.Fail("problems.list", 3, "<html>502 Bad Gateway</html>")
+	cfg := srv.Config()
+	cfg.MaxAttempts = 2
+	pc := polygon.NewPolygon(cfg)
+
+	_, err := pc.GetProblem(42)
+	require.ErrorIs(t, err, polygon.ErrTooManyAttempts)
+	require.ErrorIs(t, err, polygon.ErrBadResponse)
+}
+
+func TestRetryRaw(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	prob.Files["problem.html"] = &fakepolygon.File{Type: polygon.TypeResource, Content: "<html>A+B</html>"}
+	prob.Solutions["main.cpp"] = &fakepolygon.Solution{Tag: polygon.TagMain, Content: "int main() {}"}
+	srv.Fail("problem.viewFile", 1, "<html>502 Bad Gateway</html>")
+	cfg := srv.Config()
+	cfg.MaxAttempts = 2
+	pc := polygon.NewPolygon(cfg)
+
+	data, err := pc.ViewFile(42, polygon.TypeResource, "problem.html")
+	require.NoError(t, err)
+	require.Equal(t, "<html>A+B</html>", data)
+
+	srv.FailStatus("problem.viewSolution", 2, http.StatusForbidden)
+	_, err = pc.ViewSolution(42, "main.cpp")
+	require.ErrorIs(t, err, polygon.ErrTooManyAttempts)
+	require.ErrorIs(t, err, polygon.ErrBadResponse)
+	var apiErr *polygon.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, polygon.KindBadResponse, apiErr.Kind)
+	require.Contains(t, apiErr.Comment, "403 Forbidden")
+	data, err = pc.ViewSolution(42, "main.cpp")
+	require.NoError(t, err)
+	require.Equal(t, "int main() {}", data)
+}
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+	require.NoError(t, pc.SaveTest(polygon.NewTestRequest(42, 1).Input("1 2")))
+
+	err := pc.SaveTest(polygon.NewTestRequest(42, 1).Input("3 4").CheckExisting(true))
+	require.ErrorIs(t, err, polygon.ErrBadPolygonStatus)
+	require.ErrorIs(t, err, polygon.ErrAlreadyExists)
+	var apiErr *polygon.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "problem.saveTest", apiErr.Method)
+	require.Equal(t, polygon.KindAlreadyExists, apiErr.Kind)
+	require.Equal(t, "1", apiErr.Params.Get("testIndex"))
+	require.False(t, apiErr.Params.Has("apiKey"))
+	require.False(t, apiErr.Params.Has("apiSig"))
+
+	_, err = pc.GetTests(43, polygon.DefaultTestset)
+	require.ErrorIs(t, err, polygon.ErrNotFound)
+	require.ErrorIs(t, err, polygon.ErrProblemNotFound)
+
+	err = pc.SetChecker(42, "check.cpp")
+	require.ErrorIs(t, err, polygon.ErrNotFound)
+	require.NotErrorIs(t, err, polygon.ErrProblemNotFound)
+}
+
+func TestAPIErrorKind(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	cfg := srv.Config()
+	cfg.MaxAttempts = 1
+	pc := polygon.NewPolygon(cfg)
+
+	for _, tc := range []struct {
+		comment string
+		kind    polygon.ErrorKind
+		problem bool
+	}{
+		{"Access denied", polygon.KindAccessDenied, false},
+		{"Unknown access type: WRITE", polygon.KindUnknown, false},
+		{"Problem is locked by another user", polygon.KindLocked, false},
+		{"Problem is unlocked, nothing to commit", polygon.KindUnknown, false},
+		{"Blocked by administrator", polygon.KindUnknown, false},
+		{"Rate limit exceeded", polygon.KindThrottled, false},
+		{"problemId: Problem not found", polygon.KindNotFound, true},
+		{"Problem 42 not found", polygon.KindNotFound, true},
+		{"name: File not found", polygon.KindNotFound, false},
+		{"Test index is not foundational", polygon.KindUnknown, false},
+	} {
+		srv.Fail("problem.commitChanges", 1, fmt.Sprintf(`{"status":"FAILED","comment":%q}`, tc.comment))
+		err := pc.Commit(42, true, tc.comment)
+		var apiErr *polygon.APIError
+		require.ErrorAs(t, err, &apiErr, tc.comment)
+		require.Equal(t, tc.kind, apiErr.Kind, tc.comment)
+		require.Equal(t, tc.kind.Temporary(), errors.Is(err, polygon.ErrTooManyAttempts), tc.comment)
+		require.Equal(t, tc.problem, errors.Is(err, polygon.ErrProblemNotFound), tc.comment)
+	}
+}
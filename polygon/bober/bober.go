@@ -0,0 +1,361 @@
+package bober
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/facette/natsort"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	megabyte = 1024 * 1024
+
+	testsPattern   = "tests/%02d"
+	answersPattern = "tests/%02d.a"
+	answerSuffix   = ".a"
+)
+
+var (
+	ErrNoTests       = errors.New("no tests found")
+	ErrNoMain        = errors.New("exactly one main solution is required")
+	ErrAmbiguous     = errors.New("glob matches several files")
+	ErrUnknownSource = errors.New("unknown source type")
+	ErrDuplicate     = errors.New("duplicate package file")
+	ErrGroupOverlap  = errors.New("test belongs to several groups")
+	ErrBadPolicy     = errors.New("bad points policy")
+)
+
+type Bober struct {
+	fsys    fs.FS
+	conv    *Conventions
+	mapping *polygon.Mapping
+	pkg     vydra.Package
+	prob    vydra.ProblemXML
+}
+
+// Problem directory is read from fsys, e.g. os.DirFS.
+func NewBober(fsys fs.FS, conv *Conventions) *Bober {
+	return &Bober{
+		fsys:    fsys,
+		conv:    conv,
+		mapping: polygon.DefaultMapping(),
+	}
+}
+
+// Override the embedded solution tags and source types mapping.
+func (b *Bober) UseMapping(m *polygon.Mapping) {
+	b.mapping = m
+}
+
+func (b *Bober) glob(pattern string) ([]string, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	files, err := fs.Glob(b.fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	natsort.Sort(files)
+
+	return files, nil
+}
+
+// Empty string if nothing matched.
+func (b *Bober) single(pattern string) (string, error) {
+	files, err := b.glob(pattern)
+	if err != nil {
+		return "", err
+	}
+	switch len(files) {
+	case 0:
+		return "", nil
+	case 1:
+		return files[0], nil
+	default:
+		return "", fmt.Errorf("%w: %s %v", ErrAmbiguous, pattern, files)
+	}
+}
+
+func (b *Bober) copyFile(src, dst string) error {
+	if _, ok := b.pkg[dst]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicate, dst)
+	}
+	data, err := fs.ReadFile(b.fsys, src)
+	if err != nil {
+		return err
+	}
+	b.pkg[dst] = data
+	logrus.WithFields(logrus.Fields{"src": src, "dst": dst}).Info("add file")
+
+	return nil
+}
+
+func (b *Bober) source(src, dir string) (vydra.Source, error) {
+	typ := b.mapping.ExtensionType(src)
+	if typ == "" {
+		return vydra.Source{}, fmt.Errorf("%w: %s", ErrUnknownSource, src)
+	}
+	dst := path.Join(dir, path.Base(src))
+
+	return vydra.Source{Path: dst, Type: typ}, b.copyFile(src, dst)
+}
+
+func (b *Bober) Build() (vydra.Package, error) {
+	b.pkg = make(vydra.Package)
+	b.prob = vydra.ProblemXML{Revision: 1, ShortName: b.conv.Name}
+	b.prob.Judging.InputFile = b.conv.InputFile
+	b.prob.Judging.OutputFile = b.conv.OutputFile
+	for _, tag := range b.conv.Tags {
+		b.prob.Tags.Tags = append(b.prob.Tags.Tags, vydra.Tag{Value: tag})
+	}
+	for _, build := range []func() error{b.addResources, b.addExecutables, b.addSolutions, b.addTests} {
+		if err := build(); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := b.prob.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	b.pkg["problem.xml"] = data
+
+	return b.pkg, nil
+}
+
+func (b *Bober) addResources() error {
+	files, err := b.glob(b.conv.Resources)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		dst := path.Join("files", path.Base(file))
+		if err := b.copyFile(file, dst); err != nil {
+			return err
+		}
+		res := vydra.File{Path: dst, Type: b.mapping.ExtensionType(file)}
+		b.prob.Files.Resources.Files = append(b.prob.Files.Resources.Files, res)
+	}
+
+	return nil
+}
+
+func (b *Bober) executable(src string) (*vydra.Source, error) {
+	source, err := b.source(src, "files")
+	if err != nil {
+		return nil, err
+	}
+	exe := vydra.Executable{Source: source}
+	b.prob.Files.Executables.Executables = append(b.prob.Files.Executables.Executables, exe)
+
+	return &source, nil
+}
+
+// Generators, validator, checker and interactor.
+func (b *Bober) addExecutables() error {
+	gens, err := b.glob(b.conv.Generators)
+	if err != nil {
+		return err
+	}
+	for _, gen := range gens {
+		if _, err := b.executable(gen); err != nil {
+			return err
+		}
+	}
+
+	assets := &b.prob.Assets
+	if val, err := b.single(b.conv.Validator); err != nil {
+		return err
+	} else if val != "" {
+		source, err := b.executable(val)
+		if err != nil {
+			return err
+		}
+		assets.Validators.Validator = &vydra.Validator{Source: *source}
+	}
+	if chk, err := b.single(b.conv.Checker); err != nil {
+		return err
+	} else if chk != "" {
+		source, err := b.executable(chk)
+		if err != nil {
+			return err
+		}
+		assets.Checker = &vydra.Checker{Type: "testlib", Source: source}
+	} else if b.conv.StdChecker != "" {
+		assets.Checker = &vydra.Checker{Name: b.conv.StdChecker, Type: "testlib"}
+	}
+	if inter, err := b.single(b.conv.Interactor); err != nil {
+		return err
+	} else if inter != "" {
+		source, err := b.executable(inter)
+		if err != nil {
+			return err
+		}
+		assets.Interactor = &vydra.Interactor{Type: "testlib", Source: *source}
+	}
+
+	return nil
+}
+
+func (b *Bober) addSolutions() error {
+	tags := make([]string, 0, len(b.conv.Solutions))
+	for tag := range b.conv.Solutions {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	mains := 0
+	for _, tag := range tags {
+		if _, err := b.mapping.SolutionTag(tag); err != nil {
+			return err
+		}
+		files, err := b.glob(b.conv.Solutions[tag])
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			source, err := b.source(file, "solutions")
+			if err != nil {
+				return err
+			}
+			sol := vydra.Solution{Tag: tag, Source: source}
+			b.prob.Assets.Solutions.Solutions = append(b.prob.Assets.Solutions.Solutions, sol)
+		}
+		if tag == "main" {
+			mains += len(files)
+		}
+	}
+	if mains != 1 {
+		return fmt.Errorf("%w: found %d", ErrNoMain, mains)
+	}
+
+	return nil
+}
+
+// Answers (input name + ".a") are added only if every test has one,
+// otherwise Polygon generates them by the main solution.
+func (b *Bober) addTests() error {
+	matched, err := b.glob(b.conv.Tests)
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(matched))
+	for _, file := range matched {
+		exists[file] = true
+	}
+	var files []string
+	answers := 0
+	for _, file := range matched {
+		if strings.HasSuffix(file, answerSuffix) && exists[strings.TrimSuffix(file, answerSuffix)] {
+			answers++
+
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("%w: %s", ErrNoTests, b.conv.Tests)
+	}
+	withAnswers := answers == len(files)
+	if answers != 0 && !withAnswers {
+		logrus.WithFields(logrus.Fields{
+			"tests": len(files), "answers": answers,
+		}).Warn("not all tests have answers, skip answers")
+	}
+	tests := make([]vydra.Test, len(files))
+	for idx, file := range files {
+		if err := b.copyFile(file, fmt.Sprintf(testsPattern, idx+1)); err != nil {
+			return err
+		}
+		if withAnswers {
+			if err := b.copyFile(file+answerSuffix, fmt.Sprintf(answersPattern, idx+1)); err != nil {
+				return err
+			}
+		}
+		tests[idx] = vydra.Test{Method: "manual", Description: fmt.Sprintf("File \"%s\"", path.Base(file))}
+	}
+	if b.conv.Samples != "" {
+		first, last, err := parseRange(b.conv.Samples, len(tests))
+		if err != nil {
+			return err
+		}
+		for idx := first; idx <= last; idx++ {
+			tests[idx-1].Sample = true
+		}
+	}
+
+	testset := vydra.TestSet{
+		Name:             polygon.DefaultTestset,
+		TimeLimit:        b.conv.TimeLimit,
+		MemoryLimit:      b.conv.MemoryLimit * megabyte,
+		TestCount:        len(tests),
+		InputPathPattern: testsPattern,
+	}
+	if withAnswers {
+		testset.AnswerPathPattern = answersPattern
+	}
+	for _, spec := range b.conv.Groups {
+		group, err := addGroup(tests, &spec)
+		if err != nil {
+			return err
+		}
+		testset.Groups.Groups = append(testset.Groups.Groups, *group)
+	}
+	testset.Tests.Tests = tests
+	b.prob.Judging.TestSets = []vydra.TestSet{testset}
+	logrus.WithFields(logrus.Fields{
+		"tests": len(tests), "groups": len(b.conv.Groups),
+	}).Info("add testset")
+
+	return nil
+}
+
+// Complete group points are set to the last test of the group.
+func addGroup(tests []vydra.Test, spec *GroupSpec) (*vydra.Group, error) {
+	first, last, err := parseRange(spec.Tests, len(tests))
+	if err != nil {
+		return nil, fmt.Errorf("group %s: %w", spec.Name, err)
+	}
+	group := &vydra.Group{
+		Name:           spec.Name,
+		Points:         spec.Points,
+		PointsPolicy:   spec.PointsPolicy,
+		FeedbackPolicy: spec.FeedbackPolicy,
+	}
+	if group.PointsPolicy == "" {
+		group.PointsPolicy = vydra.PointsCompleteGroup
+	}
+	if group.FeedbackPolicy == "" {
+		group.FeedbackPolicy = vydra.FeedbackComplete
+	}
+	for idx := first; idx <= last; idx++ {
+		test := &tests[idx-1]
+		if test.Group != "" {
+			return nil, fmt.Errorf("%w: test %d in groups %s and %s", ErrGroupOverlap, idx, test.Group, spec.Name)
+		}
+		test.Group = spec.Name
+		switch group.PointsPolicy {
+		case vydra.PointsCompleteGroup:
+			if idx == last {
+				test.Points = spec.Points
+			}
+		case vydra.PointsEachTest:
+			test.Points = spec.Points / float32(last-first+1)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrBadPolicy, group.PointsPolicy)
+		}
+	}
+	for _, dep := range spec.Dependencies {
+		group.Dependencies.Dependencies = append(group.Dependencies.Dependencies, struct {
+			Group string `xml:"group,attr"`
+		}{Group: dep})
+	}
+
+	return group, nil
+}
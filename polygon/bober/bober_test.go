@@ -0,0 +1,107 @@
+package bober_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/bober"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/stretchr/testify/require"
+)
+
+func build(t *testing.T, fsys fstest.MapFS) (vydra.Package, error) {
+	t.Helper()
+	conv, err := bober.LoadConventions(fsys, "bober.json")
+	require.NoError(t, err)
+
+	return bober.NewBober(fsys, conv).Build()
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+	fsys := os.DirFS("testdata/sum")
+	conv, err := bober.LoadConventions(fsys, "bober.json")
+	require.NoError(t, err)
+	pkg, err := bober.NewBober(fsys, conv).Build()
+	require.NoError(t, err)
+	require.Equal(t, "100 100\n", string(pkg["tests/04"]))
+	require.Contains(t, pkg, "files/testlib.h")
+	require.Contains(t, pkg, "solutions/minus.py")
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, pkg.WriteZip(buf))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "sum")
+	rep, err := vydra.NewVydra(polygon.NewPolygon(srv.Config()), zr, 42, false, 4).Upload()
+	require.NoError(t, err)
+	require.Empty(t, rep.Errors)
+
+	require.Equal(t, 2000, prob.TimeLimit)
+	require.Equal(t, []string{"math"}, prob.Tags)
+	require.Equal(t, "std::wcmp.cpp", prob.Checker)
+	require.Equal(t, "val.cpp", prob.Validator)
+	require.Equal(t, polygon.TagMain, prob.Solutions["sol.cpp"].Tag)
+	require.Equal(t, polygon.TagWrongAnswer, prob.Solutions["minus.py"].Tag)
+	require.Equal(t, "python.3", prob.Solutions["minus.py"].SourceType)
+
+	tests := prob.Tests[polygon.DefaultTestset]
+	require.Len(t, tests, 4)
+	require.True(t, tests[1].UseInStatements)
+	require.Equal(t, "2 3\n", tests[2].Input)
+	require.Equal(t, "1", tests[4].Group)
+	require.InDelta(t, 100, tests[4].Points, 0)
+	require.Equal(t, []string{"0"}, prob.Groups[polygon.DefaultTestset]["1"].Dependencies)
+}
+
+func TestBuildAnswers(t *testing.T) {
+	t.Parallel()
+	sol := &fstest.MapFile{Data: []byte("int main() {}")}
+	test := &fstest.MapFile{Data: []byte("1\n")}
+
+	pkg, err := build(t, fstest.MapFS{
+		"sol.cpp": sol, "tests/1": test, "tests/1.a": test, "tests/beta": test, "tests/beta.a": test,
+	})
+	require.NoError(t, err)
+	require.Contains(t, pkg, "tests/02")
+	require.Contains(t, pkg, "tests/02.a")
+	require.Contains(t, string(pkg["problem.xml"]), "<answer-path-pattern>tests/%02d.a</answer-path-pattern>")
+
+	pkg, err = build(t, fstest.MapFS{"sol.cpp": sol, "tests/1": test, "tests/1.a": test, "tests/2.": test})
+	require.NoError(t, err)
+	require.Contains(t, pkg, "tests/02")
+	require.NotContains(t, pkg, "tests/01.a")
+	require.NotContains(t, string(pkg["problem.xml"]), "answer-path-pattern")
+}
+
+func TestBuildErrors(t *testing.T) {
+	t.Parallel()
+	sol := &fstest.MapFile{Data: []byte("int main() {}")}
+	test := &fstest.MapFile{Data: []byte("1\n")}
+
+	_, err := build(t, fstest.MapFS{"tests/1": test})
+	require.ErrorIs(t, err, bober.ErrNoMain)
+	_, err = build(t, fstest.MapFS{"sol.cpp": sol})
+	require.ErrorIs(t, err, bober.ErrNoTests)
+	_, err = build(t, fstest.MapFS{"sol.cpp": sol, "sol.rs": sol, "tests/1": test})
+	require.ErrorIs(t, err, bober.ErrUnknownSource)
+	_, err = build(t, fstest.MapFS{"sol.cpp": sol, "val.cpp": sol, "val2.cpp": sol, "tests/1": test})
+	require.ErrorIs(t, err, bober.ErrAmbiguous)
+
+	groups := `{"groups": [{"name": "1", "tests": "1-2"}, {"name": "2", "tests": "2"}]}`
+	_, err = build(t, fstest.MapFS{
+		"sol.cpp": sol, "tests/1": test, "tests/2": test, "bober.json": {Data: []byte(groups)},
+	})
+	require.ErrorIs(t, err, bober.ErrGroupOverlap)
+	_, err = build(t, fstest.MapFS{
+		"sol.cpp": sol, "tests/1": test, "bober.json": {Data: []byte(groups)},
+	})
+	require.ErrorIs(t, err, bober.ErrBadRange)
+}
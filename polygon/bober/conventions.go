@@ -0,0 +1,106 @@
+package bober
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultTL = 1000
+	defaultML = 256
+)
+
+var ErrBadRange = errors.New("bad tests range")
+
+// Group of tests, Points is the total score of the group.
+type GroupSpec struct {
+	Name           string   `json:"name"`
+	Tests          string   `json:"tests"` // e.g. 2-10
+	Points         float32  `json:"points"`
+	PointsPolicy   string   `json:"pointsPolicy,omitempty"`   // complete-group or each-test
+	FeedbackPolicy string   `json:"feedbackPolicy,omitempty"` // complete, icpc, points or none
+	Dependencies   []string `json:"dependencies,omitempty"`
+}
+
+// Local directory layout, globs are relative to the problem directory.
+type Conventions struct {
+	Name        string            `json:"name"` // directory name by default
+	TimeLimit   int               `json:"timeLimit"`
+	MemoryLimit int               `json:"memoryLimit"` // megabytes
+	InputFile   string            `json:"inputFile"`
+	OutputFile  string            `json:"outputFile"`
+	Resources   string            `json:"resources"`
+	Generators  string            `json:"generators"`
+	Validator   string            `json:"validator"`
+	Checker     string            `json:"checker"`
+	StdChecker  string            `json:"stdChecker"` // used if checker is not found
+	Interactor  string            `json:"interactor"`
+	Solutions   map[string]string `json:"solutions"` // problem.xml tag -> glob
+	Tests       string            `json:"tests"`     // answers are matched by ".a" suffix
+	Samples     string            `json:"samples"`   // e.g. 1-2
+	Groups      []GroupSpec       `json:"groups"`
+	Tags        []string          `json:"tags"`
+}
+
+func DefaultConventions() *Conventions {
+	return &Conventions{
+		TimeLimit:   defaultTL,
+		MemoryLimit: defaultML,
+		Resources:   "*.h",
+		Generators:  "gen*.cpp",
+		Validator:   "val*.cpp",
+		Checker:     "check*.cpp",
+		StdChecker:  "std::wcmp.cpp",
+		Interactor:  "inter*.cpp",
+		Solutions: map[string]string{
+			"main":                "sol.*",
+			"accepted":            "solutions/ok/*",
+			"rejected":            "solutions/incor/*",
+			"wrong-answer":        "solutions/wa/*",
+			"time-limit-exceeded": "solutions/tl/*",
+		},
+		Tests: "tests/*",
+	}
+}
+
+// Fields from the file override the default ones, missing file means defaults.
+func LoadConventions(fsys fs.FS, name string) (*Conventions, error) {
+	conv := DefaultConventions()
+	data, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return conv, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, conv); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return conv, nil
+}
+
+// Inclusive 1-based range, e.g. 3-7 or 5.
+func parseRange(s string, count int) (int, int, error) {
+	first, last, ok := strings.Cut(s, "-")
+	if !ok {
+		last = first
+	}
+	l, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %s", ErrBadRange, s)
+	}
+	r, err := strconv.Atoi(strings.TrimSpace(last))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %s", ErrBadRange, s)
+	}
+	if l < 1 || l > r || r > count {
+		return 0, 0, fmt.Errorf("%w: %s of %d tests", ErrBadRange, s, count)
+	}
+
+	return l, r, nil
+}
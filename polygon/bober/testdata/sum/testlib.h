@@ -0,0 +1 @@
+// testlib stub
@@ -0,0 +1,10 @@
+#include "testlib.h"
+
+int main(int argc, char* argv[]) {
+    registerValidation(argc, argv);
+    inf.readInt(1, 100);
+    inf.readSpace();
+    inf.readInt(1, 100);
+    inf.readEoln();
+    inf.readEof();
+}
@@ -0,0 +1,248 @@
+package enot
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/valeria"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/sirupsen/logrus"
+)
+
+// CMS task directory in italy_yaml format, loaded by cmsImportTask.
+// See https://cms.readthedocs.io/en/latest/External%20contest%20formats.html.
+const (
+	cmsInput     = "input/input%d.txt"
+	cmsOutput    = "output/output%d.txt"
+	cmsGen       = "gen/GEN"
+	cmsStatement = "statement/statement.pdf"
+
+	scoreGroupMin = "GroupMin"
+	scoreGroupSum = "GroupSum"
+)
+
+func (e *Enot) exportCMS() error {
+	ts, err := e.testset()
+	if err != nil {
+		return err
+	}
+	files, err := e.testFiles(ts)
+	if err != nil {
+		return err
+	}
+	subtasks, err := cmsSubtasks(ts)
+	if err != nil {
+		return err
+	}
+	scoreType, err := cmsScoreType(subtasks)
+	if err != nil {
+		return err
+	}
+	order := cmsOrder(len(files), subtasks)
+	for idx, test := range order {
+		if err := e.copyFile(files[test].input, fmt.Sprintf(cmsInput, idx)); err != nil {
+			return err
+		}
+		if err := e.copyFile(files[test].answer, fmt.Sprintf(cmsOutput, idx)); err != nil {
+			return err
+		}
+	}
+	if err := e.cmsTask(ts, order, subtasks, scoreType); err != nil {
+		return err
+	}
+	if len(subtasks) != 0 {
+		e.cmsGen(subtasks)
+	}
+	for _, build := range []func() error{e.cmsChecker, e.cmsSolution, e.cmsStatement} {
+		if err := build(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Groups sorted by the first test, the same scoring rules as in valeria.
+func cmsSubtasks(ts *vydra.TestSet) ([]valeria.GroupScore, error) {
+	if len(ts.Groups.Groups) == 0 {
+		return nil, nil
+	}
+	tests := make([]polygon.TestAnswer, 0, len(ts.Tests.Tests))
+	for idx, test := range ts.Tests.Tests {
+		if test.Group == "" {
+			return nil, fmt.Errorf("%w: %d", ErrUngroupedTest, idx+1)
+		}
+		tests = append(tests, polygon.TestAnswer{
+			Index:           idx + 1,
+			Group:           test.Group,
+			Points:          test.Points,
+			UseInStatements: test.Sample,
+		})
+	}
+	groups := make([]polygon.GroupAnswer, 0, len(ts.Groups.Groups))
+	for _, g := range ts.Groups.Groups {
+		policy := polygon.PolicyCompleteGroup
+		if g.PointsPolicy == vydra.PointsEachTest {
+			policy = polygon.PolicyEachTest
+		}
+		deps := make([]string, 0, len(g.Dependencies.Dependencies))
+		for _, dep := range g.Dependencies.Dependencies {
+			deps = append(deps, dep.Group)
+		}
+		groups = append(groups, polygon.GroupAnswer{Name: g.Name, PointsPolicy: policy, Dependencies: deps})
+	}
+	subtasks, err := valeria.GroupScores(tests, groups)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(subtasks, func(i, j int) bool {
+		return subtasks[i].Tests[0] < subtasks[j].Tests[0]
+	})
+	for _, st := range subtasks {
+		if len(st.Dependencies) != 0 {
+			logrus.WithFields(logrus.Fields{"group": st.Name, "dependencies": st.Dependencies}).
+				Warn("group dependencies are not supported by CMS, skip")
+		}
+	}
+
+	return subtasks, nil
+}
+
+// Score type is the same for all subtasks, groups without points fit any.
+func cmsScoreType(subtasks []valeria.GroupScore) (string, error) {
+	scoreType := ""
+	for _, st := range subtasks {
+		if st.Score == 0 {
+			continue
+		}
+		cur := scoreGroupMin
+		if st.EachTest {
+			cur = scoreGroupSum
+		}
+		if scoreType != "" && scoreType != cur {
+			return "", fmt.Errorf("%w: group %s", ErrMixedPolicy, st.Name)
+		}
+		scoreType = cur
+	}
+	if scoreType == "" {
+		scoreType = scoreGroupMin
+	}
+
+	return scoreType, nil
+}
+
+// Tests are renumbered from 0 so that every subtask is contiguous, order[new] = old (0-based).
+func cmsOrder(count int, subtasks []valeria.GroupScore) []int {
+	order := make([]int, 0, count)
+	for _, st := range subtasks {
+		for _, idx := range st.Tests {
+			order = append(order, idx-1)
+		}
+	}
+	if len(subtasks) == 0 {
+		for idx := range count {
+			order = append(order, idx)
+		}
+	}
+
+	return order
+}
+
+func (e *Enot) cmsTask(ts *vydra.TestSet, order []int, subtasks []valeria.GroupScore, scoreType string) error {
+	task := cmsTaskYAML{
+		Name:        e.prob.ShortName,
+		Title:       e.problemName(),
+		TimeLimit:   float64(ts.TimeLimit) / msInSec,
+		MemoryLimit: ts.MemoryLimit / megabyte,
+		NInput:      len(ts.Tests.Tests),
+		Infile:      e.prob.Judging.InputFile,
+		Outfile:     e.prob.Judging.OutputFile,
+	}
+	var samples []string
+	for idx, test := range order {
+		if ts.Tests.Tests[test].Sample {
+			samples = append(samples, strconv.Itoa(idx))
+		}
+	}
+	task.PublicTestcases = strings.Join(samples, ",")
+	if len(subtasks) != 0 {
+		task.ScoreType = scoreType
+		for _, st := range subtasks {
+			task.ScoreTypeParameters = append(task.ScoreTypeParameters, [2]int{st.Score, len(st.Tests)})
+		}
+	}
+	data, err := marshalYAML(&task)
+	if err != nil {
+		return err
+	}
+	e.pkg["task.yaml"] = data
+
+	return nil
+}
+
+// Every subtask starts with "# ST: score", tests are already in input directory.
+func (e *Enot) cmsGen(subtasks []valeria.GroupScore) {
+	var sb strings.Builder
+	idx := 0
+	for _, st := range subtasks {
+		fmt.Fprintf(&sb, "# ST: %d\n", st.Score)
+		for range st.Tests {
+			fmt.Fprintf(&sb, "#COPY: %s\n", fmt.Sprintf(cmsInput, idx))
+			idx++
+		}
+	}
+	e.pkg[cmsGen] = []byte(sb.String())
+}
+
+// Testlib checker is copied as is, it should be adapted for CMS and compiled into check/checker.
+func (e *Enot) cmsChecker() error {
+	chk := e.prob.Assets.Checker
+	if e.prob.Assets.Interactor != nil {
+		logrus.Warn("interactive problems are not supported, skip interactor")
+	}
+	if chk == nil || chk.Source == nil {
+		return nil
+	}
+	for _, file := range append([]string{chk.Source.Path}, e.headers()...) {
+		if err := e.copyFile(file, path.Join("check", path.Base(file))); err != nil {
+			return err
+		}
+	}
+	logrus.WithField("checker", chk.Source.Path).Warn("compile checker into check/checker by hand")
+
+	return nil
+}
+
+func (e *Enot) cmsSolution() error {
+	for _, sol := range e.prob.Assets.Solutions.Solutions {
+		if sol.Tag == "main" {
+			return e.copyFile(sol.Source.Path, path.Join("sol", path.Base(sol.Source.Path)))
+		}
+	}
+
+	return nil
+}
+
+// CMS shows PDF statement, english one is preferred.
+func (e *Enot) cmsStatement() error {
+	var pdf string
+	for _, st := range e.prob.Statements.Statements {
+		if st.Type != "application/pdf" {
+			continue
+		}
+		if pdf == "" || st.Language == "english" {
+			pdf = st.Path
+		}
+	}
+	if pdf == "" {
+		logrus.Warn("no pdf statement, build package with statements")
+
+		return nil
+	}
+
+	return e.copyFile(pdf, cmsStatement)
+}
@@ -0,0 +1,250 @@
+package enot
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/facette/natsort"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	FormatKattis = "kattis"
+	FormatCMS    = "cms"
+
+	megabyte = 1024 * 1024
+	msInSec  = 1000
+)
+
+var (
+	ErrUnknownFormat  = errors.New("unknown format")
+	ErrNoTestset      = errors.New("no tests testset")
+	ErrMissingTest    = errors.New("missing test file")
+	ErrNoImport       = errors.New("import is not supported")
+	ErrMixedPolicy    = errors.New("complete group and each test groups can't be mixed")
+	ErrUngroupedTest  = errors.New("test without group")
+	ErrNoTests        = errors.New("no tests found")
+	ErrNoMain         = errors.New("no main solution")
+	ErrUnknownSource  = errors.New("unknown source type")
+	ErrUnknownChecker = errors.New("standard checker can't be converted")
+)
+
+// Polygon language -> ISO 639-1 code.
+//
+//nolint:gochecknoglobals // statement languages
+var languageCodes = map[string]string{
+	"english":   "en",
+	"russian":   "ru",
+	"ukrainian": "uk",
+	"kazakh":    "kk",
+	"german":    "de",
+	"french":    "fr",
+	"spanish":   "es",
+	"chinese":   "zh",
+}
+
+//nolint:gochecknoglobals // statement sections
+var sectionNames = []string{"name", "legend", "input", "output", "scoring", "interaction", "notes", "tutorial"}
+
+// Converter between Polygon packages and other problem formats.
+type Enot struct {
+	fsys    fs.FS
+	mapping *polygon.Mapping
+	prob    *vydra.ProblemXML
+	pkg     vydra.Package
+}
+
+// Source package (or directory in other format) is read from fsys.
+func NewEnot(fsys fs.FS) *Enot {
+	return &Enot{
+		fsys:    fsys,
+		mapping: polygon.DefaultMapping(),
+	}
+}
+
+// Override the embedded solution tags and source types mapping.
+func (e *Enot) UseMapping(m *polygon.Mapping) {
+	e.mapping = m
+}
+
+// Convert Polygon package into format.
+func (e *Enot) Export(format string) (vydra.Package, error) {
+	prob, err := vydra.ReadProblemXML(e.fsys, "problem.xml")
+	if err != nil {
+		return nil, err
+	}
+	e.prob = prob
+	e.pkg = make(vydra.Package)
+	switch format {
+	case FormatKattis:
+		err = e.exportKattis()
+	case FormatCMS:
+		err = e.exportCMS()
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return e.pkg, nil
+}
+
+// Convert problem in format into Polygon package, ready for vydra.
+func (e *Enot) Import(format string) (vydra.Package, error) {
+	e.prob = &vydra.ProblemXML{Revision: 1}
+	e.pkg = make(vydra.Package)
+	var err error
+	switch format {
+	case FormatKattis:
+		err = e.importKattis()
+	case FormatCMS:
+		err = fmt.Errorf("%w: %s", ErrNoImport, format)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := e.prob.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	e.pkg["problem.xml"] = data
+
+	return e.pkg, nil
+}
+
+func (e *Enot) copyFile(src, dst string) error {
+	data, err := fs.ReadFile(e.fsys, src)
+	if err != nil {
+		return err
+	}
+	e.pkg[dst] = data
+	logrus.WithFields(logrus.Fields{"src": src, "dst": dst}).Info("add file")
+
+	return nil
+}
+
+func (e *Enot) glob(pattern string) ([]string, error) {
+	files, err := fs.Glob(e.fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	natsort.Sort(files)
+
+	return files, nil
+}
+
+func (e *Enot) testset() (*vydra.TestSet, error) {
+	idx := slices.IndexFunc(e.prob.Judging.TestSets, func(ts vydra.TestSet) bool {
+		return ts.Name == polygon.DefaultTestset
+	})
+	if idx == -1 {
+		return nil, ErrNoTestset
+	}
+
+	return &e.prob.Judging.TestSets[idx], nil
+}
+
+type testFiles struct {
+	input  string
+	answer string
+}
+
+// Full package is required, generated tests and answers should be there.
+func (e *Enot) testFiles(ts *vydra.TestSet) ([]testFiles, error) {
+	inPattern, ansPattern := ts.InputPathPattern, ts.AnswerPathPattern
+	if inPattern == "" {
+		inPattern = path.Join(ts.Name, "%02d")
+	}
+	if ansPattern == "" {
+		ansPattern = inPattern + ".a"
+	}
+	files := make([]testFiles, 0, len(ts.Tests.Tests))
+	for idx := range ts.Tests.Tests {
+		tf := testFiles{input: fmt.Sprintf(inPattern, idx+1), answer: fmt.Sprintf(ansPattern, idx+1)}
+		for _, name := range []string{tf.input, tf.answer} {
+			if _, err := fs.Stat(e.fsys, name); err != nil {
+				return nil, fmt.Errorf("%w: %s, build full package", ErrMissingTest, name)
+			}
+		}
+		files = append(files, tf)
+	}
+
+	return files, nil
+}
+
+// Statement sections by language from statement-sections.
+func (e *Enot) statements() map[string]map[string]string {
+	res := make(map[string]map[string]string)
+	for _, st := range e.prob.Statements.Statements {
+		sections := make(map[string]string)
+		for _, name := range sectionNames {
+			data, err := fs.ReadFile(e.fsys, path.Join("statement-sections", st.Language, name+".tex"))
+			if err == nil {
+				sections[name] = strings.TrimSpace(string(data))
+			}
+		}
+		if len(sections) != 0 {
+			res[st.Language] = sections
+		}
+	}
+
+	return res
+}
+
+func languageCode(lang string) string {
+	if code, ok := languageCodes[lang]; ok {
+		return code
+	}
+
+	return lang
+}
+
+func languageName(code string) string {
+	for lang, c := range languageCodes {
+		if c == code {
+			return lang
+		}
+	}
+
+	return code
+}
+
+// Resource headers (e.g. testlib.h) are needed to compile checker and validator.
+func (e *Enot) headers() []string {
+	var res []string
+	for _, f := range e.prob.Files.Resources.Files {
+		if path.Ext(f.Path) == ".h" {
+			res = append(res, f.Path)
+		}
+	}
+
+	return res
+}
+
+// Points of the groups, complete group points are spread over its tests.
+func groupPoints(ts *vydra.TestSet) map[string]float32 {
+	res := make(map[string]float32)
+	for _, test := range ts.Tests.Tests {
+		res[test.Group] += test.Points
+	}
+
+	return res
+}
+
+func groupCounts(ts *vydra.TestSet) map[string]int {
+	res := make(map[string]int)
+	for _, test := range ts.Tests.Tests {
+		res[test.Group]++
+	}
+
+	return res
+}
@@ -0,0 +1,178 @@
+package enot_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/enot"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKattis(t *testing.T) {
+	t.Parallel()
+	pkg, err := enot.NewEnot(os.DirFS("../vydra/testdata/aplusb")).Export(enot.FormatKattis)
+	require.NoError(t, err)
+	require.Equal(t, "name: A+B\ntype: scoring\nvalidation: custom\nlimits:\n  memory: 256\n", string(pkg["problem.yaml"]))
+	require.Equal(t, "2\n", string(pkg[".timelimit"]))
+	require.Equal(t, "1 2\n", string(pkg["data/sample/01.in"]))
+	require.NotContains(t, pkg, "data/secret/group01/01.in") // sample group has no points
+	require.NotContains(t, pkg, "data/secret/group01/testdata.yaml")
+	require.Contains(t, pkg, "data/secret/group02/03.ans")
+	require.Equal(t, "accept_score: 100\ngrader_flags: min\non_reject: break\n", string(pkg["data/secret/group02/testdata.yaml"]))
+	require.Contains(t, pkg, "output_validators/check/testlib.h")
+	require.Contains(t, pkg, "input_validators/val/val.cpp")
+	require.Contains(t, pkg, "submissions/accepted/main.cpp")
+	require.Contains(t, pkg, "submissions/wrong_answer/overflow.cpp")
+	require.Contains(t, pkg, "problem_statement/pic.png")
+	statement := string(pkg["problem_statement/problem.en.tex"])
+	require.True(t, strings.HasPrefix(statement, "\\problemname{A+B}\n\nGiven two numbers"))
+	require.Contains(t, statement, "\\section*{Output}\n\nPrint $a + b$.\n")
+
+	_, err = enot.NewEnot(os.DirFS("../vydra/testdata/aplusb")).Export("codeforces")
+	require.ErrorIs(t, err, enot.ErrUnknownFormat)
+
+	// Round trip through Kattis back into Polygon.
+	kattis, err := pkg.FS()
+	require.NoError(t, err)
+	back, err := enot.NewEnot(kattis).Import(enot.FormatKattis)
+	require.NoError(t, err)
+	fsys, err := back.FS()
+	require.NoError(t, err)
+
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	rep, err := vydra.NewVydra(polygon.NewPolygon(srv.Config()), fsys, 42, false, 4).Upload()
+	require.NoError(t, err)
+	require.Empty(t, rep.Errors)
+	require.Equal(t, 2000, prob.TimeLimit)
+	require.Equal(t, "check.cpp", prob.Checker)
+	require.Equal(t, polygon.TagMain, prob.Solutions["main.cpp"].Tag)
+	require.Equal(t, polygon.TagWrongAnswer, prob.Solutions["overflow.cpp"].Tag)
+	require.Equal(t, "Print $a + b$.\n", prob.Statements["english"]["output"])
+	require.Equal(t, "A+B\n", prob.Statements["russian"]["name"])
+
+	tests := prob.Tests[polygon.DefaultTestset]
+	require.Len(t, tests, 3)
+	require.True(t, tests[1].UseInStatements)
+	require.Equal(t, "samples", tests[1].Group)
+	require.Equal(t, "group02", tests[3].Group)
+	require.InDelta(t, 100, tests[3].Points, 0)
+	require.Equal(t, polygon.PolicyCompleteGroup, prob.Groups[polygon.DefaultTestset]["group02"].PointsPolicy)
+}
+
+func TestKattisImportYAML(t *testing.T) {
+	t.Parallel()
+	file := func(data string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(data)} }
+	pkg, err := enot.NewEnot(fstest.MapFS{
+		"problem.yaml": file(`source: |
+  Some contest
+  2024
+name:
+  ru: Сумма
+  en: 'Sum: easy'
+type: scoring
+validator_flags: [float_tolerance, 1e-6]
+limits: {memory: 512}
+`),
+		"data/sample/1.in":                 file("1 2\n"),
+		"data/sample/1.ans":                file("3\n"),
+		"data/secret/g1/1.in":              file("2 2\n"),
+		"data/secret/g1/testdata.yaml":     file("accept_score: 40 # all tests\ngrader_flags: \"min\"\n"),
+		"submissions/accepted/a.cpp":       file("int main() {}"),
+		"problem_statement/problem.en.tex": file("Add numbers.\n"),
+	}).Import(enot.FormatKattis)
+	require.NoError(t, err)
+	fsys, err := pkg.FS()
+	require.NoError(t, err)
+	prob, err := vydra.ReadProblemXML(fsys, "problem.xml")
+	require.NoError(t, err)
+	require.Equal(t, "std::rcmp6.cpp", prob.Assets.Checker.Name)
+	ts := prob.Judging.TestSets[0]
+	require.Equal(t, 512*1024*1024, ts.MemoryLimit)
+	require.Len(t, ts.Groups.Groups, 2)
+	require.InDelta(t, 40, ts.Groups.Groups[1].Points, 0)
+	require.Equal(t, vydra.PointsCompleteGroup, ts.Groups.Groups[1].PointsPolicy)
+	require.Equal(t, "Sum: easy\n", string(pkg["statement-sections/english/name.tex"]))
+
+	_, err = enot.NewEnot(fstest.MapFS{"problem.yaml": file("name: [\n")}).Import(enot.FormatKattis)
+	require.Error(t, err)
+}
+
+func TestKattisCheckerFlags(t *testing.T) {
+	t.Parallel()
+	file := func(data string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(data)} }
+	kattis := func(flags string) fstest.MapFS {
+		return fstest.MapFS{
+			"problem.yaml":               file("name: Sum\nvalidator_flags: " + flags + "\n"),
+			"data/sample/1.in":           file("1 2\n"),
+			"data/sample/1.ans":          file("3\n"),
+			"data/secret/2.in":           file("2 2\n"),
+			"data/secret/2.ans":          file("4\n"),
+			"submissions/accepted/a.cpp": file("int main() {}"),
+		}
+	}
+	for flags, checker := range map[string]string{
+		"float_absolute_tolerance 1.5e-5":       "std::rncmp.cpp",
+		"float_tolerance 1e-9":                  "std::rcmp9.cpp",
+		"case_sensitive":                        "std::wcmp.cpp",
+		"case_sensitive space_change_sensitive": "std::fcmp.cpp",
+	} {
+		pkg, err := enot.NewEnot(kattis(flags)).Import(enot.FormatKattis)
+		require.NoError(t, err, flags)
+		fsys, err := pkg.FS()
+		require.NoError(t, err)
+		prob, err := vydra.ReadProblemXML(fsys, "problem.xml")
+		require.NoError(t, err)
+		require.Equal(t, checker, prob.Assets.Checker.Name, flags)
+
+		back, err := enot.NewEnot(fsys).Export(enot.FormatKattis)
+		require.NoError(t, err, flags)
+		require.Contains(t, string(back["problem.yaml"]), "validator_flags: "+flags+"\n")
+	}
+
+	_, err := enot.NewEnot(kattis("float_relative_tolerance 1e-3")).Import(enot.FormatKattis)
+	require.ErrorIs(t, err, enot.ErrUnknownChecker)
+	pkg, err := enot.NewEnot(kattis("float_tolerance 1e-9")).Import(enot.FormatKattis)
+	require.NoError(t, err)
+	pkg["problem.xml"] = bytes.ReplaceAll(pkg["problem.xml"], []byte("std::rcmp9.cpp"), []byte("std::uncmp.cpp"))
+	fsys, err := pkg.FS()
+	require.NoError(t, err)
+	_, err = enot.NewEnot(fsys).Export(enot.FormatKattis)
+	require.ErrorIs(t, err, enot.ErrUnknownChecker)
+}
+
+func TestCMS(t *testing.T) {
+	t.Parallel()
+	pkg, err := enot.NewEnot(os.DirFS("../vydra/testdata/aplusb")).Export(enot.FormatCMS)
+	require.NoError(t, err)
+	require.Equal(t, `name: aplusb
+title: A+B
+time_limit: 2
+memory_limit: 256
+n_input: 3
+infile: ""
+outfile: ""
+public_testcases: "0"
+score_type: GroupMin
+score_type_parameters: [[0, 1], [100, 2]]
+`, string(pkg["task.yaml"]))
+	require.Equal(t, `# ST: 0
+#COPY: input/input0.txt
+# ST: 100
+#COPY: input/input1.txt
+#COPY: input/input2.txt
+`, string(pkg["gen/GEN"]))
+	require.Equal(t, "1 2\n", string(pkg["input/input0.txt"]))
+	require.Contains(t, pkg, "output/output2.txt")
+	require.Contains(t, pkg, "check/check.cpp")
+	require.Contains(t, pkg, "sol/main.cpp")
+
+	_, err = enot.NewEnot(os.DirFS("../vydra/testdata/aplusb")).Import(enot.FormatCMS)
+	require.ErrorIs(t, err, enot.ErrNoImport)
+}
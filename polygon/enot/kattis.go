@@ -0,0 +1,662 @@
+package enot
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/facette/natsort"
+	"github.com/sirupsen/logrus"
+)
+
+// Kattis problem package format, as used by DOMjudge.
+// See https://www.kattis.com/problem-package-format/spec/legacy.html.
+const (
+	kattisSample    = "data/sample"
+	kattisSecret    = "data/secret"
+	kattisStatement = "problem_statement"
+	kattisTestdata  = "testdata.yaml"
+
+	defaultTL      = 1000
+	defaultML      = 256
+	defaultAccept  = 1
+	sampleGroup    = "samples"
+	secretGroup    = "secret"
+	defaultChecker = "std::wcmp.cpp"
+)
+
+//nolint:gochecknoglobals // kattis statement sections
+var (
+	kattisSections = []struct{ name, title string }{
+		{"input", "Input"}, {"output", "Output"}, {"interaction", "Interaction"},
+		{"scoring", "Scoring"}, {"notes", "Notes"},
+	}
+	reKattisSection = regexp.MustCompile(`\\section\*?\{(Input|Output|Interaction|Scoring|Notes)\}`)
+	reProblemName   = regexp.MustCompile(`\\problemname\{(.*)\}`)
+)
+
+func (e *Enot) exportKattis() error {
+	ts, err := e.testset()
+	if err != nil {
+		return err
+	}
+	files, err := e.testFiles(ts)
+	if err != nil {
+		return err
+	}
+	scoring := false
+	for _, test := range ts.Tests.Tests {
+		scoring = scoring || test.Points != 0
+	}
+	if err := e.kattisProblem(ts, scoring); err != nil {
+		return err
+	}
+	if err := e.kattisTests(ts, files, scoring); err != nil {
+		return err
+	}
+	for _, build := range []func() error{e.kattisValidators, e.kattisSubmissions, e.kattisStatements} {
+		if err := build(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Enot) problemName() string {
+	statements := e.statements()
+	if st, ok := statements["english"]; ok && st["name"] != "" {
+		return st["name"]
+	}
+	for _, st := range e.prob.Statements.Statements {
+		if name := statements[st.Language]["name"]; name != "" {
+			return name
+		}
+	}
+
+	return e.prob.ShortName
+}
+
+// Standard testlib checkers are replaced by default output validator flags.
+// Kattis default validator ignores case, so token checkers need case_sensitive.
+// The first checker with the flags is used for import.
+//
+//nolint:gochecknoglobals // testlib checkers
+var stdCheckers = []struct{ name, flags string }{
+	{"wcmp", "case_sensitive"},
+	{"lcmp", "case_sensitive"},
+	{"ncmp", "case_sensitive"},
+	{"icmp", "case_sensitive"},
+	{"hcmp", "case_sensitive"},
+	{"fcmp", "case_sensitive space_change_sensitive"},
+	{"yesno", ""},
+	{"nyesno", ""},
+	{"rcmp4", "float_tolerance 1e-4"},
+	{"rcmp6", "float_tolerance 1e-6"},
+	{"rcmp9", "float_tolerance 1e-9"},
+	{"dcmp", "float_tolerance 1e-6"},
+	{"rcmp", "float_absolute_tolerance 1.5e-6"},
+	{"acmp", "float_absolute_tolerance 1.5e-6"},
+	{"rncmp", "float_absolute_tolerance 1.5e-5"},
+}
+
+func stdCheckerFlags(name string) (string, error) {
+	short := strings.TrimSuffix(strings.TrimPrefix(name, "std::"), ".cpp")
+	for _, chk := range stdCheckers {
+		if chk.name == short {
+			return chk.flags, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownChecker, name)
+}
+
+// Validator without flags compares tokens ignoring case, wcmp is the closest one.
+func stdChecker(flags string) (string, error) {
+	flags = strings.Join(strings.Fields(flags), " ")
+	if flags == "" {
+		return defaultChecker, nil
+	}
+	for _, chk := range stdCheckers {
+		if chk.flags == flags {
+			return fmt.Sprintf("std::%s.cpp", chk.name), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: validator_flags %s", ErrUnknownChecker, flags)
+}
+
+func (e *Enot) kattisProblem(ts *vydra.TestSet, scoring bool) error {
+	meta := kattisProblemYAML{Name: yamlName{name: e.problemName()}}
+	if scoring {
+		meta.Type = "scoring"
+	}
+	chk := e.prob.Assets.Checker
+	switch {
+	case e.prob.Assets.Interactor != nil:
+		meta.Validation = "custom interactive"
+	case chk != nil && chk.Source != nil:
+		meta.Validation = "custom"
+	case chk != nil:
+		meta.Validation = "default"
+		flags, err := stdCheckerFlags(chk.Name)
+		if err != nil {
+			return err
+		}
+		meta.ValidatorFlags = yamlFlags(flags)
+	}
+	meta.Limits.Memory = ts.MemoryLimit / megabyte
+	data, err := marshalYAML(&meta)
+	if err != nil {
+		return err
+	}
+	e.pkg["problem.yaml"] = data
+	// DOMjudge reads time limit from .timelimit.
+	e.pkg[".timelimit"] = []byte(strconv.FormatFloat(float64(ts.TimeLimit)/msInSec, 'g', -1, 64) + "\n")
+
+	return nil
+}
+
+// Groups become subdirectories of data/secret, scores are set in testdata.yaml.
+func (e *Enot) kattisTests(ts *vydra.TestSet, files []testFiles, scoring bool) error {
+	points := groupPoints(ts)
+	dirs := make(map[string]string)
+	for idx, g := range ts.Groups.Groups {
+		dirs[g.Name] = path.Join(kattisSecret, fmt.Sprintf("group%02d", idx+1))
+	}
+	used := make(map[string]bool)
+	width := max(len(strconv.Itoa(len(files))), 2) //nolint:mnd // polygon default
+	for idx, test := range ts.Tests.Tests {
+		name := fmt.Sprintf("%0*d", width, idx+1)
+		var targets []string
+		if test.Sample {
+			targets = append(targets, kattisSample)
+		}
+		if !test.Sample || (scoring && points[test.Group] != 0) {
+			dir, ok := dirs[test.Group]
+			if !ok {
+				dir = kattisSecret
+			}
+			targets = append(targets, dir)
+			used[dir] = true
+		}
+		for _, dir := range targets {
+			if err := e.copyFile(files[idx].input, path.Join(dir, name+".in")); err != nil {
+				return err
+			}
+			if err := e.copyFile(files[idx].answer, path.Join(dir, name+".ans")); err != nil {
+				return err
+			}
+		}
+	}
+	if scoring {
+		return e.kattisTestdata(ts, dirs, used)
+	}
+
+	return nil
+}
+
+func (e *Enot) kattisTestdata(ts *vydra.TestSet, dirs map[string]string, used map[string]bool) error {
+	points := groupPoints(ts)
+	counts := groupCounts(ts)
+	for _, g := range ts.Groups.Groups {
+		if !used[dirs[g.Name]] {
+			continue
+		}
+		var meta kattisTestdataYAML
+		score := float64(points[g.Name])
+		switch g.PointsPolicy {
+		case vydra.PointsEachTest:
+			score /= float64(counts[g.Name])
+			meta.GraderFlags, meta.OnReject = "sum", "continue"
+		default:
+			meta.GraderFlags, meta.OnReject = "min", "break"
+		}
+		meta.AcceptScore = &score
+		data, err := marshalYAML(&meta)
+		if err != nil {
+			return err
+		}
+		e.pkg[path.Join(dirs[g.Name], kattisTestdata)] = data
+	}
+
+	return nil
+}
+
+func (e *Enot) kattisProgram(dir string, source string) error {
+	stem := strings.TrimSuffix(path.Base(source), path.Ext(source))
+	for _, file := range append([]string{source}, e.headers()...) {
+		if err := e.copyFile(file, path.Join(dir, stem, path.Base(file))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Testlib sources are copied as is, they should be adapted for Kattis API by hand.
+func (e *Enot) kattisValidators() error {
+	assets := &e.prob.Assets
+	if inter := assets.Interactor; inter != nil {
+		if err := e.kattisProgram("output_validators", inter.Source.Path); err != nil {
+			return err
+		}
+	} else if chk := assets.Checker; chk != nil && chk.Source != nil {
+		if err := e.kattisProgram("output_validators", chk.Source.Path); err != nil {
+			return err
+		}
+	}
+	if val := assets.Validators.Validator; val != nil {
+		return e.kattisProgram("input_validators", val.Source.Path)
+	}
+
+	return nil
+}
+
+func (e *Enot) kattisSubmissions() error {
+	for _, sol := range e.prob.Assets.Solutions.Solutions {
+		dir, ok := e.mapping.KattisVerdict(sol.Tag)
+		if !ok {
+			logrus.WithFields(logrus.Fields{"path": sol.Source.Path, "tag": sol.Tag}).
+				Warn("no kattis verdict for solution tag, skip")
+
+			continue
+		}
+		if err := e.copyFile(sol.Source.Path, path.Join("submissions", dir, path.Base(sol.Source.Path))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Enot) kattisStatements() error {
+	for lang, sections := range e.statements() {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "\\problemname{%s}\n\n%s\n", sections["name"], sections["legend"])
+		for _, sec := range kattisSections {
+			if text := sections[sec.name]; text != "" {
+				fmt.Fprintf(&sb, "\n\\section*{%s}\n\n%s\n", sec.title, text)
+			}
+		}
+		e.pkg[path.Join(kattisStatement, fmt.Sprintf("problem.%s.tex", languageCode(lang)))] = []byte(sb.String())
+
+		entries, err := fs.ReadDir(e.fsys, path.Join("statement-sections", lang))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || path.Ext(name) == ".tex" || strings.HasPrefix(name, "example.") {
+				continue
+			}
+			if err := e.copyFile(path.Join("statement-sections", lang, name), path.Join(kattisStatement, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *Enot) importKattis() error {
+	var meta kattisProblemYAML
+	if err := e.readYAML("problem.yaml", &meta); err != nil {
+		return err
+	}
+	ts := vydra.TestSet{
+		Name:              "tests",
+		TimeLimit:         defaultTL,
+		MemoryLimit:       defaultML * megabyte,
+		InputPathPattern:  "tests/%02d",
+		AnswerPathPattern: "tests/%02d.a",
+	}
+	if meta.Limits.Memory != 0 {
+		ts.MemoryLimit = meta.Limits.Memory * megabyte
+	}
+	if data, err := fs.ReadFile(e.fsys, ".timelimit"); err == nil {
+		if tl, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64); err == nil {
+			ts.TimeLimit = int(tl * msInSec)
+		}
+	}
+	if err := e.importTests(&ts, meta.Type == "scoring"); err != nil {
+		return err
+	}
+	e.prob.Judging.TestSets = []vydra.TestSet{ts}
+	for _, build := range []func() error{
+		e.importSubmissions,
+		func() error { return e.importValidators(&meta) },
+		func() error { return e.importStatements(meta.Name) },
+	} {
+		if err := build(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type kattisTest struct {
+	input  string
+	sample bool
+	group  string
+}
+
+// Secret tests by directory relative to data/secret, the root directory is empty.
+func (e *Enot) secretTests() (map[string][]string, error) {
+	res := make(map[string][]string)
+	err := fs.WalkDir(e.fsys, kattisSecret, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(file) != ".in" {
+			return err
+		}
+		dir := strings.TrimPrefix(strings.TrimPrefix(path.Dir(file), kattisSecret), "/")
+		res[dir] = append(res[dir], file)
+
+		return nil
+	})
+
+	return res, err
+}
+
+func (e *Enot) importTests(ts *vydra.TestSet, scoring bool) error {
+	samples, err := e.glob(path.Join(kattisSample, "*.in"))
+	if err != nil {
+		return err
+	}
+	secret, err := e.secretTests()
+	if err != nil {
+		return err
+	}
+	groups := make([]string, 0, len(secret))
+	for g := range secret {
+		groups = append(groups, g)
+	}
+	natsort.Sort(groups)
+	withGroups := scoring && (len(groups) > 1 || (len(groups) == 1 && groups[0] != ""))
+
+	var tests []kattisTest
+	for _, file := range samples {
+		tests = append(tests, kattisTest{input: file, sample: true, group: sampleGroup})
+	}
+	if withGroups && len(samples) != 0 {
+		ts.Groups.Groups = append(ts.Groups.Groups, vydra.Group{
+			Name: sampleGroup, PointsPolicy: vydra.PointsEachTest, FeedbackPolicy: vydra.FeedbackComplete,
+		})
+	}
+	for _, g := range groups {
+		files := secret[g]
+		natsort.Sort(files)
+		name := strings.ReplaceAll(g, "/", "-")
+		if name == "" {
+			name = secretGroup
+		}
+		for _, file := range files {
+			tests = append(tests, kattisTest{input: file, group: name})
+		}
+		if withGroups {
+			group, err := e.kattisGroup(g, name, len(files))
+			if err != nil {
+				return err
+			}
+			ts.Groups.Groups = append(ts.Groups.Groups, *group)
+		}
+	}
+	if len(tests) == 0 {
+		return fmt.Errorf("%w: %s", ErrNoTests, kattisSecret)
+	}
+
+	return e.addKattisTests(ts, tests, withGroups)
+}
+
+// Kattis group score is accept_score of each test, min grader means complete group.
+func (e *Enot) kattisGroup(dir, name string, count int) (*vydra.Group, error) {
+	var meta kattisTestdataYAML
+	file := path.Join(kattisSecret, dir, kattisTestdata)
+	if _, err := fs.Stat(e.fsys, file); err == nil {
+		if err := e.readYAML(file, &meta); err != nil {
+			return nil, err
+		}
+	}
+	accept := float64(defaultAccept)
+	if meta.AcceptScore != nil {
+		accept = *meta.AcceptScore
+	}
+	group := &vydra.Group{Name: name, FeedbackPolicy: vydra.FeedbackComplete}
+	if strings.Contains(string(meta.GraderFlags), "min") {
+		group.PointsPolicy = vydra.PointsCompleteGroup
+		group.Points = float32(accept)
+	} else {
+		group.PointsPolicy = vydra.PointsEachTest
+		group.Points = float32(accept) * float32(count)
+	}
+
+	return group, nil
+}
+
+func (e *Enot) addKattisTests(ts *vydra.TestSet, tests []kattisTest, withGroups bool) error {
+	groups := make(map[string]*vydra.Group)
+	last := make(map[string]int)
+	for i := range ts.Groups.Groups {
+		groups[ts.Groups.Groups[i].Name] = &ts.Groups.Groups[i]
+	}
+	for idx, kt := range tests {
+		if err := e.copyFile(kt.input, fmt.Sprintf(ts.InputPathPattern, idx+1)); err != nil {
+			return err
+		}
+		answer := strings.TrimSuffix(kt.input, ".in") + ".ans"
+		if _, err := fs.Stat(e.fsys, answer); err == nil {
+			if err := e.copyFile(answer, fmt.Sprintf(ts.AnswerPathPattern, idx+1)); err != nil {
+				return err
+			}
+		}
+		test := vydra.Test{Method: "manual", Sample: kt.sample}
+		if withGroups {
+			test.Group = kt.group
+			last[kt.group] = idx
+		}
+		ts.Tests.Tests = append(ts.Tests.Tests, test)
+	}
+	counts := groupCounts(ts)
+	for name, g := range groups {
+		for idx := range ts.Tests.Tests {
+			test := &ts.Tests.Tests[idx]
+			if test.Group != name {
+				continue
+			}
+			if g.PointsPolicy == vydra.PointsEachTest {
+				test.Points = g.Points / float32(counts[name])
+			} else if idx == last[name] {
+				test.Points = g.Points
+			}
+		}
+	}
+	ts.TestCount = len(ts.Tests.Tests)
+	logrus.WithFields(logrus.Fields{"tests": ts.TestCount, "groups": len(groups)}).Info("import tests")
+
+	return nil
+}
+
+func (e *Enot) importSource(src, dir string) (*vydra.Source, error) {
+	typ := e.mapping.ExtensionType(src)
+	if typ == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSource, src)
+	}
+	source := &vydra.Source{Path: path.Join(dir, path.Base(src)), Type: typ}
+
+	return source, e.copyFile(src, source.Path)
+}
+
+// The first accepted submission becomes the main solution.
+func (e *Enot) importSubmissions() error {
+	files, err := e.glob("submissions/*/*")
+	if err != nil {
+		return err
+	}
+	hasMain := false
+	for _, file := range files {
+		dir := path.Base(path.Dir(file))
+		tag, ok := e.mapping.KattisTag(dir)
+		if !ok {
+			logrus.WithField("path", file).Warn("unknown kattis verdict, skip")
+
+			continue
+		}
+		source, err := e.importSource(file, "solutions")
+		if err != nil {
+			logrus.WithError(err).Warn("skip submission")
+
+			continue
+		}
+		if tag == "accepted" && !hasMain {
+			tag, hasMain = "main", true
+		}
+		sol := vydra.Solution{Tag: tag, Source: *source}
+		e.prob.Assets.Solutions.Solutions = append(e.prob.Assets.Solutions.Solutions, sol)
+	}
+	if !hasMain {
+		return fmt.Errorf("%w: no accepted submissions", ErrNoMain)
+	}
+
+	return nil
+}
+
+// Program directory contains a single source and optional headers.
+func (e *Enot) importProgram(pattern string) (*vydra.Source, error) {
+	files, err := e.glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var source *vydra.Source
+	for _, file := range files {
+		if path.Ext(file) == ".h" {
+			dst := path.Join("files", path.Base(file))
+			if _, ok := e.pkg[dst]; ok {
+				continue
+			}
+			if err := e.copyFile(file, dst); err != nil {
+				return nil, err
+			}
+			res := vydra.File{Path: dst, Type: e.mapping.ExtensionType(file)}
+			e.prob.Files.Resources.Files = append(e.prob.Files.Resources.Files, res)
+
+			continue
+		}
+		if source != nil {
+			logrus.WithField("path", file).Warn("several sources in program directory, skip")
+
+			continue
+		}
+		if source, err = e.importSource(file, "files"); err != nil {
+			return nil, err
+		}
+		exe := vydra.Executable{Source: *source}
+		e.prob.Files.Executables.Executables = append(e.prob.Files.Executables.Executables, exe)
+	}
+
+	return source, nil
+}
+
+func (e *Enot) importValidators(meta *kattisProblemYAML) error {
+	source, err := e.importProgram("output_validators/*/*")
+	if err != nil {
+		return err
+	}
+	assets := &e.prob.Assets
+	switch {
+	case source != nil && strings.Contains(meta.Validation, "interactive"):
+		assets.Interactor = &vydra.Interactor{Type: "testlib", Source: *source}
+	case source != nil:
+		assets.Checker = &vydra.Checker{Type: "testlib", Source: source}
+	default:
+		name, err := stdChecker(string(meta.ValidatorFlags))
+		if err != nil {
+			return err
+		}
+		assets.Checker = &vydra.Checker{Name: name, Type: "testlib"}
+	}
+	source, err = e.importProgram("input_validators/*/*")
+	if err != nil {
+		return err
+	}
+	if source != nil {
+		assets.Validators.Validator = &vydra.Validator{Source: *source}
+	}
+
+	return nil
+}
+
+func splitKattisStatement(text string) map[string]string {
+	sections := make(map[string]string)
+	if m := reProblemName.FindStringSubmatch(text); m != nil {
+		sections["name"] = m[1]
+		text = strings.Replace(text, m[0], "", 1)
+	}
+	locs := reKattisSection.FindAllStringSubmatchIndex(text, -1)
+	sections["legend"] = text
+	if len(locs) != 0 {
+		sections["legend"] = text[:locs[0][0]]
+	}
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		sections[strings.ToLower(text[loc[2]:loc[3]])] = text[loc[1]:end]
+	}
+	for sec, body := range sections {
+		sections[sec] = strings.TrimSpace(body)
+	}
+
+	return sections
+}
+
+// Statement sections for vydra, problem.tex is kept for reference.
+func (e *Enot) importStatements(name yamlName) error {
+	files, err := e.glob(path.Join(kattisStatement, "problem*.tex"))
+	if err != nil {
+		return err
+	}
+	resources, err := e.glob(path.Join(kattisStatement, "*"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		code := strings.TrimSuffix(strings.TrimPrefix(path.Base(file), "problem"), ".tex")
+		lang := "english"
+		if code != "" {
+			lang = languageName(strings.TrimPrefix(code, "."))
+		}
+		data, err := fs.ReadFile(e.fsys, file)
+		if err != nil {
+			return err
+		}
+		sections := splitKattisStatement(string(data))
+		if sections["name"] == "" {
+			sections["name"] = name.get(languageCode(lang))
+		}
+		dir := path.Join("statement-sections", lang)
+		for sec, text := range sections {
+			if text != "" {
+				e.pkg[path.Join(dir, sec+".tex")] = []byte(text + "\n")
+			}
+		}
+		for _, res := range resources {
+			if path.Ext(res) != ".tex" {
+				if err := e.copyFile(res, path.Join(dir, path.Base(res))); err != nil {
+					return err
+				}
+			}
+		}
+		texPath := path.Join("statements", lang, "problem.tex")
+		e.pkg[texPath] = data
+		e.prob.Statements.Statements = append(e.prob.Statements.Statements, vydra.Statement{
+			Charset: "UTF-8", Language: lang, Path: texPath, Type: "application/x-tex",
+		})
+	}
+
+	return nil
+}
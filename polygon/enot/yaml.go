@@ -0,0 +1,133 @@
+package enot
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const yamlIndent = 2
+
+// Kattis problem.yaml, unknown keys are ignored.
+type kattisProblemYAML struct {
+	Name           yamlName  `yaml:"name"`
+	Type           string    `yaml:"type,omitempty"`
+	Validation     string    `yaml:"validation,omitempty"`
+	ValidatorFlags yamlFlags `yaml:"validator_flags,omitempty"`
+	Limits         struct {
+		Memory int `yaml:"memory,omitempty"` // megabytes
+	} `yaml:"limits"`
+}
+
+// Kattis testdata.yaml of the test group.
+type kattisTestdataYAML struct {
+	AcceptScore *float64  `yaml:"accept_score,omitempty"`
+	GraderFlags yamlFlags `yaml:"grader_flags,omitempty"`
+	OnReject    string    `yaml:"on_reject,omitempty"`
+}
+
+// CMS task.yaml in italy_yaml format.
+type cmsTaskYAML struct {
+	Name                string   `yaml:"name"`
+	Title               string   `yaml:"title"`
+	TimeLimit           float64  `yaml:"time_limit"` // seconds
+	MemoryLimit         int      `yaml:"memory_limit"`
+	NInput              int      `yaml:"n_input"`
+	Infile              string   `yaml:"infile"`
+	Outfile             string   `yaml:"outfile"`
+	PublicTestcases     string   `yaml:"public_testcases"`
+	ScoreType           string   `yaml:"score_type,omitempty"`
+	ScoreTypeParameters [][2]int `yaml:"score_type_parameters,flow,omitempty"`
+}
+
+// Flags are a string or a list of strings, joined by spaces.
+type yamlFlags string
+
+func (f *yamlFlags) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*f = yamlFlags(strings.Join(list, " "))
+
+		return nil
+	}
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	*f = yamlFlags(s)
+
+	return nil
+}
+
+// Problem name is a string or a mapping from language code to name.
+type yamlName struct {
+	name  string
+	names map[string]string
+}
+
+func (n *yamlName) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		return node.Decode(&n.names)
+	}
+
+	return node.Decode(&n.name)
+}
+
+func (n yamlName) MarshalYAML() (any, error) {
+	return n.name, nil
+}
+
+// Name in language (ISO 639-1 code), English or any other as fallback.
+func (n yamlName) get(code string) string {
+	if n.names == nil {
+		return n.name
+	}
+	for _, c := range []string{code, languageCodes["english"]} {
+		if name, ok := n.names[c]; ok {
+			return name
+		}
+	}
+	codes := make([]string, 0, len(n.names))
+	for c := range n.names {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	if len(codes) == 0 {
+		return ""
+	}
+
+	return n.names[codes[0]]
+}
+
+func marshalYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e *Enot) readYAML(name string, v any) error {
+	data, err := fs.ReadFile(e.fsys, name)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package polygon
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	maxParamLen = 64
+	maxBodyLen  = 128
+)
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAccessDenied  = errors.New("access denied")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrLocked        = errors.New("locked")
+	ErrThrottled     = errors.New("throttled")
+)
+
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	KindNotFound
+	KindAccessDenied
+	KindAlreadyExists
+	KindLocked
+	KindThrottled
+	KindBadResponse // not a Polygon API answer, e.g. HTML error page
+)
+
+//nolint:gochecknoglobals // polygon comments
+var kindPatterns = []struct {
+	kind ErrorKind
+	re   *regexp.Regexp
+}{
+	{KindThrottled, phrases("too many requests", "try again later", "rate limit", "rate limited")},
+	{KindLocked, phrases("locked", "is being modified")},
+	{KindAlreadyExists, phrases("already exists", "already exist")},
+	{KindAccessDenied, phrases("access denied", "no access", "permission denied", "no permission", "not allowed")},
+	{KindNotFound, phrases("not found", "doesn't exist", "does not exist", "no such")},
+}
+
+// Comments like "problemId: Problem not found" or "Problem 42 not found".
+//
+//nolint:gochecknoglobals // polygon comments
+var reProblemNotFound = regexp.MustCompile(`(?i)^problemId:|\bproblem(?: \d+)? (?:is )?not found\b`)
+
+// Whole words only, so "locked" doesn't match "unlocked".
+func phrases(list ...string) *regexp.Regexp {
+	quoted := make([]string, 0, len(list))
+	for _, p := range list {
+		quoted = append(quoted, regexp.QuoteMeta(p))
+	}
+
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+func classifyComment(comment string) ErrorKind {
+	for _, kp := range kindPatterns {
+		if kp.re.MatchString(comment) {
+			return kp.kind
+		}
+	}
+
+	return KindUnknown
+}
+
+func (k ErrorKind) sentinel() error {
+	switch k {
+	case KindNotFound:
+		return ErrNotFound
+	case KindAccessDenied:
+		return ErrAccessDenied
+	case KindAlreadyExists:
+		return ErrAlreadyExists
+	case KindLocked:
+		return ErrLocked
+	case KindThrottled:
+		return ErrThrottled
+	case KindBadResponse:
+		return ErrBadResponse
+	case KindUnknown:
+	}
+
+	return nil
+}
+
+func (k ErrorKind) String() string {
+	if err := k.sentinel(); err != nil {
+		return err.Error()
+	}
+
+	return "unknown"
+}
+
+// It's worth to repeat the request later.
+func (k ErrorKind) Temporary() bool {
+	return k == KindThrottled || k == KindLocked || k == KindBadResponse
+}
+
+type APIError struct {
+	Method  string
+	Params  url.Values // without secrets, long values are truncated
+	Kind    ErrorKind
+	Comment string
+}
+
+func newAPIError(method string, params url.Values, comment string) *APIError {
+	clean := make(url.Values, len(params))
+	for k, vals := range params {
+		switch k {
+		case "apiKey", "apiSig", "time":
+			continue
+		}
+		for _, v := range vals {
+			if len(v) > maxParamLen {
+				v = fmt.Sprintf("<%d bytes>", len(v))
+			}
+			clean.Add(k, v)
+		}
+	}
+
+	return &APIError{
+		Method:  method,
+		Params:  clean,
+		Kind:    classifyComment(comment),
+		Comment: comment,
+	}
+}
+
+func newResponseError(method string, params url.Values, resp *http.Response, data []byte) *APIError {
+	body := strings.Join(strings.Fields(string(data)), " ")
+	if len(body) > maxBodyLen {
+		body = body[:maxBodyLen] + "..."
+	}
+	apiErr := newAPIError(method, params, fmt.Sprintf("%s %s: %s", resp.Status, resp.Header.Get("Content-Type"), body))
+	apiErr.Kind = KindBadResponse
+
+	return apiErr
+}
+
+// Raw methods respond with files, so only status and content type tell errors apart.
+func isErrorPage(resp *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	return resp.StatusCode/100 != 2 || mediaType == "text/html"
+}
+
+func (e *APIError) Error() string {
+	keys := make([]string, 0, len(e.Params))
+	for k := range e.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := make([]string, 0, len(keys))
+	for _, k := range keys {
+		params = append(params, fmt.Sprintf("%s=%s", k, strings.Join(e.Params[k], ",")))
+	}
+
+	return fmt.Sprintf("%s: %s(%s): %s", ErrBadPolygonStatus, e.Method, strings.Join(params, " "), e.Comment)
+}
+
+func (e *APIError) Unwrap() []error {
+	errs := []error{ErrBadPolygonStatus}
+	if err := e.Kind.sentinel(); err != nil {
+		errs = append(errs, err)
+	}
+	if e.Kind == KindNotFound && reProblemNotFound.MatchString(e.Comment) {
+		errs = append(errs, ErrProblemNotFound)
+	}
+
+	return errs
+}
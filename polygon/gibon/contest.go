@@ -0,0 +1,95 @@
+package gibon
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/facette/natsort"
+	"github.com/sirupsen/logrus"
+)
+
+// Methods which can be resolved for all problems of the contest.
+//
+//nolint:gochecknoglobals // contest methods
+var contestMethods = []string{ModeBuild, ModeCommit, ModeDownload, ModePackage, ModeUpdate}
+
+// Per-problem result of the contest-wide method.
+type Result struct {
+	Letter   string
+	ID       int
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+func (r Result) Status() string {
+	if r.Err != nil {
+		return "failed: " + r.Err.Error()
+	}
+
+	return "ok"
+}
+
+// Resolve method for every problem of the contest (pID is a contest id) using workers.
+// The error is returned only if the method can't be run, failures are in the results.
+func (g *Gibon) ResolveContest(method string, workers int) ([]Result, error) {
+	if !slices.Contains(contestMethods, method) {
+		return nil, fmt.Errorf("%w: %s for contest", ErrUnknownMethod, method)
+	}
+	probs, err := g.client.ContestProblems(g.pID)
+	if err != nil {
+		return nil, err
+	}
+	letters := make([]string, 0, len(probs))
+	for letter := range probs {
+		letters = append(letters, letter)
+	}
+	natsort.Sort(letters)
+	workers = min(max(workers, 1), len(letters))
+	logrus.WithFields(logrus.Fields{
+		"contest": g.pID, "problems": len(letters), "method": method, "workers": workers,
+	}).Info("resolve contest")
+
+	results := make([]Result, len(letters))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				prob := probs[letters[idx]]
+				results[idx] = g.resolveProblem(method, letters[idx], prob.ID, prob.Name)
+			}
+		}()
+	}
+	for idx := range letters {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+func (g *Gibon) resolveProblem(method, letter string, pID int, name string) Result {
+	opts := g.opts
+	if opts.Extract != "" { // every problem gets its own directory
+		opts.Extract = filepath.Join(opts.Extract, letter)
+	}
+	sub := &Gibon{client: g.client, pID: pID, opts: opts}
+	start := time.Now()
+	err := sub.Resolve(method)
+	res := Result{Letter: letter, ID: pID, Name: name, Err: err, Duration: time.Since(start)}
+	entry := logrus.WithFields(logrus.Fields{"letter": letter, "problem": pID, "method": method})
+	if err != nil {
+		entry.WithError(err).Error("failed")
+	} else {
+		entry.Info("done")
+	}
+
+	return res
+}
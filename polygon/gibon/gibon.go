@@ -1,50 +1,106 @@
 package gibon
 
 import (
+	"archive/zip"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
-	"slices"
+	"path/filepath"
+	"time"
 
 	"github.com/Gornak40/algolymp/polygon"
 	"github.com/sirupsen/logrus"
 )
 
 const (
+	ModeBuild    = "build"
 	ModeContest  = "contest"
 	ModeCommit   = "commit"
 	ModeDownload = "download"
+	ModeInfo     = "info"
 	ModePackage  = "package"
 	ModeUpdate   = "update"
 )
 
-const packageMode = 0666
+const (
+	packageMode = 0666
+	dirMode     = 0755
+
+	defaultInterval = 5 * time.Second
+)
+
+// Package build waiting time when Options.Timeout is zero.
+const DefaultTimeout = 10 * time.Minute
 
 var (
-	ErrNoPackage     = errors.New("no suitable package")
 	ErrUnknownMethod = errors.New("unknown method")
+	ErrBuildFailed   = errors.New("package build failed")
+	ErrBuildTimeout  = errors.New("package build timeout")
+	ErrBadArchive    = errors.New("bad package archive")
+	ErrBuildRevision = errors.New("only the current revision can be built")
 )
 
+// Deprecated: use polygon.ErrNoPackage.
+var ErrNoPackage = polygon.ErrNoPackage
+
+// Method options, zero values mean defaults.
+type Options struct {
+	Revision int           // the current problem revision by default
+	Type     string        // standard, linux (default) or windows
+	Extract  string        // extract package into directory instead of saving zip
+	Timeout  time.Duration // package build waiting time
+	Interval time.Duration // package state polling interval
+	JSON     bool          // print info as JSON
+}
+
 type Gibon struct {
 	client *polygon.Polygon
 	pID    int
+	opts   Options
 }
 
 func NewGibon(client *polygon.Polygon, pID int) *Gibon {
-	return &Gibon{
+	g := &Gibon{
 		client: client,
 		pID:    pID,
 	}
+	g.UseOptions(Options{})
+
+	return g
+}
+
+func (g *Gibon) UseOptions(opts Options) {
+	if opts.Type == "" {
+		opts.Type = polygon.PackageLinux
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Interval == 0 {
+		opts.Interval = defaultInterval
+	}
+	g.opts = opts
 }
 
 func (g *Gibon) Resolve(method string) error {
 	switch method {
+	case ModeBuild:
+		return g.resolveBuild()
 	case ModeContest:
 		return g.listProblems()
 	case ModeCommit:
 		return g.client.Commit(g.pID, true, "")
 	case ModeDownload:
 		return g.resolveDownload()
+	case ModeInfo:
+		info, err := g.Info()
+		if err != nil {
+			return err
+		}
+
+		return WriteInfo(os.Stdout, []ProblemInfo{*info}, g.opts.JSON)
 	case ModePackage:
 		return g.client.BuildPackage(g.pID, true, true)
 	case ModeUpdate:
@@ -54,41 +110,158 @@ func (g *Gibon) Resolve(method string) error {
 	return fmt.Errorf("%w: %s", ErrUnknownMethod, method)
 }
 
-func (g *Gibon) resolveDownload() error {
+func (g *Gibon) problem() (*polygon.ProblemAnswer, error) {
 	prob, err := g.client.GetProblem(g.pID) // it's for zip naming
 	if err != nil {
-		logrus.WithError(err).Fatal("failed to get problem")
+		return nil, err
 	}
 	logrus.WithFields(logrus.Fields{
 		"name": prob.Name, "owner": prob.Owner, "access": prob.AccessType,
 		"package": prob.LatestPackage, "revision": prob.Revision,
 	}).Info("problem found")
 
+	return prob, nil
+}
+
+func (g *Gibon) resolveDownload() error {
+	prob, err := g.problem()
+	if err != nil {
+		return err
+	}
+	revision := g.opts.Revision
+	if revision == 0 {
+		revision = prob.Revision
+	}
+	p, err := g.client.FindPackage(g.pID, revision, g.opts.Type)
+	if err != nil {
+		return fmt.Errorf("%w: revision %d, type %s", err, revision, g.opts.Type)
+	}
+
+	return g.download(prob.Name, p)
+}
+
+// Build full package and poll until it is ready or failed, then download it.
+// Polygon builds the current revision only, any other Options.Revision is rejected.
+func (g *Gibon) resolveBuild() error {
+	prob, err := g.problem()
+	if err != nil {
+		return err
+	}
+	if g.opts.Revision != 0 && g.opts.Revision != prob.Revision {
+		return fmt.Errorf("%w: revision %d, current %d", ErrBuildRevision, g.opts.Revision, prob.Revision)
+	}
 	pkgs, err := g.client.GetPackages(g.pID)
 	if err != nil {
 		return err
 	}
-	idx := slices.IndexFunc(pkgs, func(p polygon.PackageAnswer) bool {
-		return p.State == "READY" && p.Type == "linux" && p.Revision == prob.Revision
-	})
-	if idx == -1 {
-		return ErrNoPackage
+	lastID := 0
+	for _, p := range pkgs {
+		lastID = max(lastID, p.ID)
+	}
+	if err := g.client.BuildPackage(g.pID, true, true); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(g.opts.Timeout)
+	for {
+		p, err := g.builtPackage(lastID, prob.Revision)
+		if err != nil {
+			return err
+		}
+		if p != nil {
+			switch p.State {
+			case polygon.PackageReady:
+				return g.download(prob.Name, p)
+			case polygon.PackageFailed:
+				return fmt.Errorf("%w: %s", ErrBuildFailed, p.Comment)
+			}
+			logrus.WithFields(logrus.Fields{"package": p.ID, "state": p.State}).Info("wait for package")
+		}
+		left := time.Until(deadline)
+		if left <= 0 {
+			return fmt.Errorf("%w: %s", ErrBuildTimeout, g.opts.Timeout)
+		}
+		time.Sleep(min(g.opts.Interval, left))
 	}
-	p := pkgs[idx]
+}
+
+// Package of the revision created after lastID, nil if it's not listed yet.
+func (g *Gibon) builtPackage(lastID, revision int) (*polygon.PackageAnswer, error) {
+	pkgs, err := g.client.GetPackages(g.pID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pkgs {
+		if p.ID > lastID && p.Revision == revision {
+			return &p, nil
+		}
+	}
+
+	return nil, nil //nolint:nilnil // not built yet
+}
+
+func (g *Gibon) download(name string, p *polygon.PackageAnswer) error {
 	logrus.WithFields(logrus.Fields{
-		"revision": p.Revision, "comment": p.Comment, "type": p.Type,
+		"revision": p.Revision, "comment": p.Comment, "type": g.opts.Type,
 	}).Info("package found")
 
-	data, err := g.client.DownloadPackage(g.pID, p.ID, p.Type)
+	data, err := g.client.DownloadPackage(g.pID, p.ID, g.opts.Type)
 	if err != nil {
 		return err
 	}
-	fname := fmt.Sprintf("%s-%d-%s.zip", prob.Name, p.Revision, p.Type)
+	if g.opts.Extract != "" {
+		logrus.WithField("dir", g.opts.Extract).Info("extract package")
+
+		return extract(data, g.opts.Extract)
+	}
+	fname := fmt.Sprintf("%s-%d-%s.zip", name, p.Revision, g.opts.Type)
 	logrus.WithField("filename", fname).Info("save package")
 
 	return os.WriteFile(fname, data, packageMode)
 }
 
+func extract(data []byte, dir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if !filepath.IsLocal(f.Name) {
+			return fmt.Errorf("%w: %s", ErrBadArchive, f.Name)
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(dst, dirMode); err != nil {
+				return err
+			}
+
+			continue
+		}
+		if err := extractFile(f, dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractFile(f *zip.File, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), dirMode); err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, data, packageMode)
+}
+
 func (g *Gibon) listProblems() error {
 	probs, err := g.client.ContestProblems(g.pID)
 	if err != nil {
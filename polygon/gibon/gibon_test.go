@@ -0,0 +1,165 @@
+package gibon_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/gibon"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest // changes working directory
+func TestDownload(t *testing.T) {
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	g := gibon.NewGibon(pc, 42)
+	require.ErrorIs(t, g.Resolve(gibon.ModeDownload), gibon.ErrNoPackage) //nolint:staticcheck // deprecated alias
+	require.NoError(t, g.Resolve(gibon.ModeCommit))
+	require.NoError(t, g.Resolve(gibon.ModePackage))
+	require.NoError(t, g.Resolve(gibon.ModeDownload))
+
+	data, err := os.ReadFile("aplusb-2-linux.zip")
+	require.NoError(t, err)
+	require.Equal(t, srv.Problem(42).PackageData[1], data)
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	srv.AddContest(7, map[string]int{"A": 42})
+	pc := polygon.NewPolygon(srv.Config())
+
+	require.NoError(t, gibon.NewGibon(pc, 42).Resolve(gibon.ModeUpdate))
+	require.NoError(t, gibon.NewGibon(pc, 7).Resolve(gibon.ModeContest))
+	require.ErrorIs(t, gibon.NewGibon(pc, 42).Resolve("bad"), gibon.ErrUnknownMethod)
+	require.Equal(t, []string{"problem.updateWorkingCopy", "contest.problems"}, srv.Calls())
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	prob.BuildPolls = 3
+	pc := polygon.NewPolygon(srv.Config())
+
+	dir := t.TempDir()
+	g := gibon.NewGibon(pc, 42)
+	g.UseOptions(gibon.Options{Type: polygon.PackageWindows, Extract: dir, Interval: time.Millisecond})
+	require.NoError(t, g.Resolve(gibon.ModeBuild))
+	data, err := os.ReadFile(filepath.Join(dir, "problem.xml"))
+	require.NoError(t, err)
+	require.Equal(t, `<problem revision="1" short-name="aplusb"></problem>`, string(data))
+
+	// Older revision is still downloadable.
+	require.NoError(t, g.Resolve(gibon.ModeCommit))
+	g.UseOptions(gibon.Options{Revision: 1, Type: polygon.PackageStandard, Extract: t.TempDir()})
+	require.NoError(t, g.Resolve(gibon.ModeDownload))
+	g.UseOptions(gibon.Options{Revision: 2})
+	require.ErrorIs(t, g.Resolve(gibon.ModeDownload), polygon.ErrNoPackage)
+
+	// Only the current revision is built.
+	g.UseOptions(gibon.Options{Revision: 1, Interval: time.Millisecond})
+	require.ErrorIs(t, g.Resolve(gibon.ModeBuild), gibon.ErrBuildRevision)
+	g.UseOptions(gibon.Options{Revision: 2, Extract: t.TempDir(), Interval: time.Millisecond})
+	require.NoError(t, g.Resolve(gibon.ModeBuild))
+}
+
+func TestBuildFailed(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	prob.BuildPolls = 1
+	prob.BuildFails = true
+	g := gibon.NewGibon(polygon.NewPolygon(srv.Config()), 42)
+	g.UseOptions(gibon.Options{Interval: time.Millisecond})
+	require.ErrorIs(t, g.Resolve(gibon.ModeBuild), gibon.ErrBuildFailed)
+
+	prob.BuildPolls = 1000
+	g.UseOptions(gibon.Options{Interval: time.Millisecond, Timeout: 20 * time.Millisecond})
+	require.ErrorIs(t, g.Resolve(gibon.ModeBuild), gibon.ErrBuildTimeout)
+}
+
+func TestResolveContest(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	srv.AddProblem(43, "amulb").BuildFails = true
+	srv.AddProblem(44, "adivb")
+	srv.AddContest(7, map[string]int{"A": 42, "B": 43, "C": 44})
+	g := gibon.NewGibon(polygon.NewPolygon(srv.Config()), 7)
+
+	results, err := g.ResolveContest(gibon.ModeCommit, 2)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Equal(t, "A", results[0].Letter)
+	require.Equal(t, "ok", results[0].Status())
+	require.Equal(t, 2, srv.Problem(44).Revision)
+
+	results, err = g.ResolveContest(gibon.ModeCommit, 0) // at least one worker
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	dir := t.TempDir()
+	g.UseOptions(gibon.Options{Extract: dir, Interval: time.Millisecond})
+	results, err = g.ResolveContest(gibon.ModeBuild, 4)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	require.ErrorIs(t, results[1].Err, gibon.ErrBuildFailed)
+	require.Equal(t, 44, results[2].ID)
+	require.FileExists(t, filepath.Join(dir, "C", "problem.xml"))
+	require.NoDirExists(t, filepath.Join(dir, "B"))
+
+	_, err = g.ResolveContest(gibon.ModeContest, 2)
+	require.ErrorIs(t, err, gibon.ErrUnknownMethod)
+}
+
+func TestInfo(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	prob.Checker = "std::wcmp.cpp"
+	prob.Tags = []string{"math", "easy"}
+	srv.AddProblem(43, "amulb")
+	srv.AddContest(7, map[string]int{"B": 43, "A": 42})
+	pc := polygon.NewPolygon(srv.Config())
+	require.NoError(t, pc.EnableGroups(42, polygon.DefaultTestset))
+	require.NoError(t, pc.EnablePoints(42))
+	for i, g := range []string{"0", "1", "1"} {
+		tr := polygon.NewTestRequest(42, i+1).Input("1 2").Group(g).Points(float32(50 * i))
+		require.NoError(t, pc.SaveTest(tr))
+	}
+	require.NoError(t, pc.SaveTestGroup(polygon.NewTestGroupRequest(42, polygon.DefaultTestset, "1").
+		Dependencies([]string{"0"})))
+
+	info, err := gibon.NewGibon(pc, 42).Info()
+	require.NoError(t, err)
+	require.Equal(t, 3, info.Tests)
+	require.InDelta(t, 150, info.Points, 0)
+	require.Len(t, info.Groups, 2)
+	require.Equal(t, 2, info.Groups[1].Tests)
+
+	infos, err := gibon.NewGibon(pc, 7).ContestInfo()
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	require.Equal(t, "A", infos[0].Letter)
+	require.Equal(t, 43, infos[1].ID)
+
+	var sb strings.Builder
+	require.NoError(t, gibon.WriteInfo(&sb, infos, false))
+	require.Contains(t, sb.String(), "A. aplusb  id 42, owner fake, revision 1, modified\n")
+	require.Contains(t, sb.String(), "checker    std::wcmp.cpp\n")
+	require.Contains(t, sb.String(), "group 1    2 tests, 150 points, COMPLETE_GROUP, requires 0\n")
+	require.Contains(t, sb.String(), "\nB. amulb   id 43")
+}
@@ -0,0 +1,215 @@
+package gibon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/facette/natsort"
+)
+
+type GroupInfo struct {
+	Name         string   `json:"name"`
+	Tests        int      `json:"tests"`
+	Points       float32  `json:"points"`
+	PointsPolicy string   `json:"pointsPolicy"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// Problem overview, tests and groups are from the default testset.
+type ProblemInfo struct {
+	Letter      string                  `json:"letter,omitempty"`
+	ID          int                     `json:"id"`
+	Name        string                  `json:"name"`
+	Owner       string                  `json:"owner"`
+	Revision    int                     `json:"revision"`
+	Modified    bool                    `json:"modified"`
+	TimeLimit   int                     `json:"timeLimit"`
+	MemoryLimit int                     `json:"memoryLimit"`
+	InputFile   string                  `json:"inputFile"`
+	OutputFile  string                  `json:"outputFile"`
+	Interactive bool                    `json:"interactive"`
+	Checker     string                  `json:"checker"`
+	Validator   string                  `json:"validator"`
+	Interactor  string                  `json:"interactor,omitempty"`
+	Tags        []string                `json:"tags"`
+	Tests       int                     `json:"tests"`
+	Samples     int                     `json:"samples"`
+	Points      float32                 `json:"points"`
+	Groups      []GroupInfo             `json:"groups"`
+	Packages    []polygon.PackageAnswer `json:"packages"`
+}
+
+func (g *Gibon) Info() (*ProblemInfo, error) {
+	prob, err := g.client.GetProblem(g.pID)
+	if err != nil {
+		return nil, err
+	}
+	info := &ProblemInfo{
+		ID: prob.ID, Name: prob.Name, Owner: prob.Owner,
+		Revision: prob.Revision, Modified: prob.Modified,
+	}
+	for _, load := range []func(*ProblemInfo) error{g.loadInfo, g.loadAssets, g.loadTests} {
+		if err := load(info); err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
+
+// Info of every problem of the contest (pID is a contest id) ordered by letter.
+func (g *Gibon) ContestInfo() ([]ProblemInfo, error) {
+	probs, err := g.client.ContestProblems(g.pID)
+	if err != nil {
+		return nil, err
+	}
+	letters := make([]string, 0, len(probs))
+	for letter := range probs {
+		letters = append(letters, letter)
+	}
+	natsort.Sort(letters)
+	infos := make([]ProblemInfo, 0, len(letters))
+	for _, letter := range letters {
+		info, err := NewGibon(g.client, probs[letter].ID).Info()
+		if err != nil {
+			return nil, fmt.Errorf("problem %s: %w", letter, err)
+		}
+		info.Letter = letter
+		infos = append(infos, *info)
+	}
+
+	return infos, nil
+}
+
+func (g *Gibon) loadInfo(info *ProblemInfo) error {
+	ans, err := g.client.GetInfo(g.pID)
+	if err != nil {
+		return err
+	}
+	info.TimeLimit = ans.TimeLimit
+	info.MemoryLimit = ans.MemoryLimit
+	info.InputFile = ans.InputFile
+	info.OutputFile = ans.OutputFile
+	info.Interactive = ans.Interactive
+	if info.Tags, err = g.client.GetTags(g.pID); err != nil {
+		return err
+	}
+	info.Packages, err = g.client.GetPackages(g.pID)
+
+	return err
+}
+
+func (g *Gibon) loadAssets(info *ProblemInfo) error {
+	var err error
+	if info.Checker, err = g.client.GetChecker(g.pID); err != nil {
+		return err
+	}
+	if info.Validator, err = g.client.GetValidator(g.pID); err != nil {
+		return err
+	}
+	if info.Interactive {
+		info.Interactor, err = g.client.GetInteractor(g.pID)
+	}
+
+	return err
+}
+
+func (g *Gibon) loadTests(info *ProblemInfo) error {
+	tests, err := g.client.GetTests(g.pID, polygon.DefaultTestset)
+	if err != nil {
+		return err
+	}
+	info.Tests = len(tests)
+	counts := make(map[string]int)
+	points := make(map[string]float32)
+	for _, t := range tests {
+		info.Points += t.Points
+		if t.UseInStatements {
+			info.Samples++
+		}
+		if t.Group != "" {
+			counts[t.Group]++
+			points[t.Group] += t.Points
+		}
+	}
+	if len(counts) == 0 { // groups are disabled
+		return nil
+	}
+	groups, err := g.client.GetGroups(g.pID, polygon.DefaultTestset)
+	if err != nil {
+		return err
+	}
+	for _, gr := range groups {
+		info.Groups = append(info.Groups, GroupInfo{
+			Name: gr.Name, Tests: counts[gr.Name], Points: points[gr.Name],
+			PointsPolicy: gr.PointsPolicy, Dependencies: gr.Dependencies,
+		})
+	}
+
+	return nil
+}
+
+// Print infos as JSON array or as a table, problems are separated by an empty line.
+func WriteInfo(w io.Writer, infos []ProblemInfo, isJSON bool) error {
+	if isJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+
+		return enc.Encode(infos)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // padding
+	for idx := range infos {
+		if idx != 0 {
+			fmt.Fprintln(tw)
+		}
+		writeInfoTable(tw, &infos[idx])
+	}
+
+	return tw.Flush()
+}
+
+func writeInfoTable(w io.Writer, info *ProblemInfo) {
+	title := info.Name
+	if info.Letter != "" {
+		title = info.Letter + ". " + title
+	}
+	state := ""
+	if info.Modified {
+		state = ", modified"
+	}
+	row := func(key, format string, args ...any) {
+		fmt.Fprintf(w, "%s\t%s\n", key, fmt.Sprintf(format, args...))
+	}
+	row(title, "id %d, owner %s, revision %d%s", info.ID, info.Owner, info.Revision, state)
+	row("limits", "%d ms, %d MB", info.TimeLimit, info.MemoryLimit)
+	row("files", "%s, %s", orDash(info.InputFile), orDash(info.OutputFile))
+	row("checker", "%s", orDash(info.Checker))
+	row("validator", "%s", orDash(info.Validator))
+	if info.Interactive {
+		row("interactor", "%s", orDash(info.Interactor))
+	}
+	row("tags", "%s", orDash(strings.Join(info.Tags, ", ")))
+	row("tests", "%d (%d samples), %g points", info.Tests, info.Samples, info.Points)
+	for _, gr := range info.Groups {
+		deps := ""
+		if len(gr.Dependencies) != 0 {
+			deps = ", requires " + strings.Join(gr.Dependencies, ", ")
+		}
+		row("group "+gr.Name, "%d tests, %g points, %s%s", gr.Tests, gr.Points, gr.PointsPolicy, deps)
+	}
+	for _, p := range info.Packages {
+		row(fmt.Sprintf("package %d", p.ID), "revision %d, %s, %s", p.Revision, p.Type, p.State)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,229 @@
+package lisa
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNoGroups      = errors.New("no scored groups, enable groups in polygon")
+	ErrBadStep       = errors.New("total is not a multiple of step")
+	ErrNoAssignment  = errors.New("no scores satisfy constraints")
+	ErrUnknownPolicy = errors.New("unknown points policy")
+)
+
+// Constraints for scores of the groups with non-sample tests.
+type Constraints struct {
+	Total     int
+	Step      int  // scores are multiples of step
+	Min       int  // minimal group score
+	Max       int  // maximal group score, 0 means total
+	Monotonic bool // scores don't decrease with group order
+}
+
+// Score of the group, tests are polygon indices in order.
+type GroupScore struct {
+	Name    string
+	Policy  string
+	Tests   []int
+	Samples []int // always get 0 points
+	Score   int
+}
+
+type Lisa struct {
+	client *polygon.Polygon
+	pID    int
+	rng    *rand.Rand
+}
+
+func NewLisa(client *polygon.Polygon, pID int, seed int64) *Lisa {
+	return &Lisa{
+		client: client,
+		pID:    pID,
+		rng:    rand.New(rand.NewSource(seed)), //nolint:gosec // not for security
+	}
+}
+
+// Choose random scores among all valid assignments, policy overrides groups policy if set.
+// Groups of samples only get 0 points. Each test group score is a multiple of its tests count.
+func (l *Lisa) Generate(c *Constraints, policy string) ([]GroupScore, error) {
+	groups, err := l.loadGroups(policy)
+	if err != nil {
+		return nil, err
+	}
+	if c.Step <= 0 || c.Total%c.Step != 0 {
+		return nil, fmt.Errorf("%w: total %d, step %d", ErrBadStep, c.Total, c.Step)
+	}
+	maxScore := c.Max
+	if maxScore == 0 {
+		maxScore = c.Total
+	}
+	var scored []*GroupScore
+	var allowed [][]bool
+	for i := range groups {
+		if len(groups[i].Tests) == 0 {
+			continue
+		}
+		units := make([]bool, c.Total/c.Step+1)
+		for u := range units {
+			score := u * c.Step
+			units[u] = score >= c.Min && score <= maxScore &&
+				(groups[i].Policy != polygon.PolicyEachTest || score%len(groups[i].Tests) == 0)
+		}
+		scored = append(scored, &groups[i])
+		allowed = append(allowed, units)
+	}
+	if len(scored) == 0 {
+		return nil, ErrNoGroups
+	}
+	units, err := sample(l.rng, allowed, c.Total/c.Step, c.Monotonic)
+	if err != nil {
+		return nil, err
+	}
+	for i, g := range scored {
+		g.Score = units[i] * c.Step
+	}
+
+	return groups, nil
+}
+
+// Groups in order of their first test.
+func (l *Lisa) loadGroups(policy string) ([]GroupScore, error) {
+	switch policy {
+	case "", polygon.PolicyCompleteGroup, polygon.PolicyEachTest:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPolicy, policy)
+	}
+	tests, err := l.client.GetTests(l.pID, polygon.DefaultTestset)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tests, func(i, j int) bool { return tests[i].Index < tests[j].Index })
+	answers, err := l.client.GetGroups(l.pID, polygon.DefaultTestset)
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[string]string, len(answers))
+	for _, g := range answers {
+		policies[g.Name] = g.PointsPolicy
+		if policy != "" {
+			policies[g.Name] = policy
+		}
+	}
+	var groups []GroupScore
+	pos := make(map[string]int)
+	for _, t := range tests {
+		if t.Group == "" {
+			continue
+		}
+		idx, ok := pos[t.Group]
+		if !ok {
+			idx = len(groups)
+			pos[t.Group] = idx
+			groups = append(groups, GroupScore{Name: t.Group, Policy: policies[t.Group]})
+		}
+		if t.UseInStatements {
+			groups[idx].Samples = append(groups[idx].Samples, t.Index)
+		} else {
+			groups[idx].Tests = append(groups[idx].Tests, t.Index)
+		}
+	}
+
+	return groups, nil
+}
+
+// Save test points and groups policy (if set) into Polygon.
+// Complete group score is set to its last test, each test score is split equally.
+func (l *Lisa) Apply(groups []GroupScore, policy string) error {
+	if err := l.client.EnablePoints(l.pID); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if policy != "" {
+			tgr := polygon.NewTestGroupRequest(l.pID, polygon.DefaultTestset, g.Name).PointsPolicy(policy)
+			if err := l.client.SaveTestGroup(tgr); err != nil {
+				return err
+			}
+		}
+		for _, idx := range g.Samples {
+			if err := l.client.SaveTest(polygon.NewTestRequest(l.pID, idx).Points(0)); err != nil {
+				return err
+			}
+		}
+		for i, idx := range g.Tests {
+			var points int
+			switch {
+			case g.Policy == polygon.PolicyEachTest:
+				points = g.Score / len(g.Tests)
+			case i == len(g.Tests)-1:
+				points = g.Score
+			}
+			if err := l.client.SaveTest(polygon.NewTestRequest(l.pID, idx).Points(float32(points))); err != nil {
+				return err
+			}
+		}
+		logrus.WithFields(logrus.Fields{"group": g.Name, "score": g.Score, "tests": len(g.Tests)}).Info("group saved")
+	}
+
+	return nil
+}
+
+// Uniform random choice of units[i] (allowed[i][units[i]]) with the total sum.
+// ways[i][s][v] is the number of assignments of groups i.. with sum s and previous value v.
+func sample(rng *rand.Rand, allowed [][]bool, total int, monotonic bool) ([]int, error) {
+	n := len(allowed)
+	ways := make([][][]float64, n+1)
+	for i := range ways {
+		ways[i] = make([][]float64, total+1)
+		for s := range ways[i] {
+			ways[i][s] = make([]float64, total+1)
+		}
+	}
+	for v := range ways[n][0] {
+		ways[n][0][v] = 1
+	}
+	for i := n - 1; i >= 0; i-- {
+		for s := 0; s <= total; s++ {
+			for prev := 0; prev <= total; prev++ {
+				for v := range allowed[i] {
+					if allowed[i][v] && v <= s && (!monotonic || v >= prev) {
+						ways[i][s][prev] += ways[i+1][s-v][v]
+					}
+				}
+			}
+		}
+	}
+	if ways[0][total][0] == 0 {
+		return nil, ErrNoAssignment
+	}
+	logrus.WithField("count", ways[0][total][0]).Info("valid assignments")
+
+	res := make([]int, n)
+	s, prev := total, 0
+	for i := range n {
+		pick := rng.Float64() * ways[i][s][prev]
+		for v := range allowed[i] {
+			if !allowed[i][v] || v > s || (monotonic && v < prev) {
+				continue
+			}
+			cnt := ways[i+1][s-v][v]
+			if cnt == 0 {
+				continue
+			}
+			res[i] = v
+			if pick < cnt {
+				break
+			}
+			pick -= cnt
+		}
+		s -= res[i]
+		prev = res[i]
+	}
+
+	return res, nil
+}
@@ -0,0 +1,51 @@
+package lisa_test
+
+import (
+	"testing"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/lisa"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddScoredProblem(42, "aplusb",
+		[]string{"0", "0", "1", "1", "2", "2", "2"},
+		[]float32{7, 7, 7, 7, 7, 7, 7}, 2,
+	)
+	pc := polygon.NewPolygon(srv.Config())
+	c := &lisa.Constraints{Total: 100, Step: 5, Min: 10, Monotonic: true}
+
+	seen := make(map[int]bool)
+	for seed := range int64(20) {
+		groups, err := lisa.NewLisa(pc, 42, seed).Generate(c, polygon.PolicyEachTest)
+		require.NoError(t, err)
+		require.Len(t, groups, 3)
+		require.Zero(t, groups[0].Score)
+		require.Equal(t, 100, groups[1].Score+groups[2].Score)
+		require.Contains(t, []int{10, 40}, groups[1].Score) // multiple of 10, not more than group 2
+		seen[groups[1].Score] = true
+
+		again, err := lisa.NewLisa(pc, 42, seed).Generate(c, polygon.PolicyEachTest)
+		require.NoError(t, err)
+		require.Equal(t, groups, again)
+	}
+	require.Len(t, seen, 2)
+
+	groups, err := lisa.NewLisa(pc, 42, 1).Generate(c, polygon.PolicyEachTest)
+	require.NoError(t, err)
+	require.NoError(t, lisa.NewLisa(pc, 42, 1).Apply(groups, polygon.PolicyEachTest))
+	prob := srv.Problem(42)
+	tests := prob.Tests[polygon.DefaultTestset]
+	require.InDelta(t, 0, tests[1].Points, 0)
+	require.InDelta(t, groups[2].Score/3, tests[7].Points, 0)
+	require.Equal(t, polygon.PolicyEachTest, prob.Groups[polygon.DefaultTestset]["2"].PointsPolicy)
+
+	_, err = lisa.NewLisa(pc, 42, 1).Generate(&lisa.Constraints{Total: 100, Step: 5, Min: 60}, "")
+	require.ErrorIs(t, err, lisa.ErrNoAssignment)
+	_, err = lisa.NewLisa(pc, 42, 1).Generate(&lisa.Constraints{Total: 100, Step: 7}, "")
+	require.ErrorIs(t, err, lisa.ErrBadStep)
+}
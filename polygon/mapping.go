@@ -0,0 +1,163 @@
+package polygon
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+)
+
+var ErrBadSolutionTag = errors.New("bad solution tag")
+
+//go:embed mapping.json
+var defaultMapping []byte
+
+// Solution tag names in all formats.
+type TagRecord struct {
+	Name    string      `json:"name"`             // problem.xml tag
+	Polygon SolutionTag `json:"polygon"`          // API tag
+	Kattis  string      `json:"kattis,omitempty"` // submissions directory
+}
+
+// Compiler ids of one language, modern for polygon.codeforces.com and legacy for polygon.lksh.ru.
+// Modern and legacy ids are known ids of the language too, empty id keeps known ids as is.
+type LanguageRecord struct {
+	Polygon    []string `json:"polygon,omitempty"` // other known ids
+	Modern     string   `json:"modern,omitempty"`
+	Legacy     string   `json:"legacy,omitempty"`
+	Extensions []string `json:"extensions,omitempty"` // files get modern id, used by package builders
+}
+
+// Conversion between problem.xml names, Polygon API ids and other formats.
+// Lookups in both directions are built from the same records, the first matching record wins.
+type Mapping struct {
+	Tags      []TagRecord      `json:"tags"`
+	Languages []LanguageRecord `json:"languages"`
+
+	tags         map[string]SolutionTag
+	tagNames     map[SolutionTag]string
+	kattisExport map[string]string
+	kattisImport map[string]string
+	extensions   map[string]string
+	languages    map[string]*LanguageRecord
+}
+
+func DefaultMapping() *Mapping {
+	var m Mapping
+	if err := json.Unmarshal(defaultMapping, &m); err != nil {
+		panic(err) // embedded file is broken
+	}
+	m.build()
+
+	return &m
+}
+
+// Records from the file take precedence over the default ones, missing file means no overrides.
+func LoadMapping(path string) (*Mapping, error) {
+	m := DefaultMapping()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var over Mapping
+	if err := json.Unmarshal(data, &over); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m.Tags = append(over.Tags, m.Tags...)
+	m.Languages = append(over.Languages, m.Languages...)
+	m.build()
+
+	return m, nil
+}
+
+func setFirst[K comparable, V any](table map[K]V, key K, value V) {
+	if _, ok := table[key]; !ok {
+		table[key] = value
+	}
+}
+
+func (m *Mapping) build() {
+	m.tags = make(map[string]SolutionTag)
+	m.tagNames = make(map[SolutionTag]string)
+	m.kattisExport = make(map[string]string)
+	m.kattisImport = make(map[string]string)
+	for _, r := range m.Tags {
+		setFirst(m.tags, r.Name, r.Polygon)
+		setFirst(m.tagNames, r.Polygon, r.Name)
+		if r.Kattis != "" {
+			setFirst(m.kattisExport, r.Name, r.Kattis)
+			setFirst(m.kattisImport, r.Kattis, r.Name)
+		}
+	}
+	m.extensions = make(map[string]string)
+	m.languages = make(map[string]*LanguageRecord)
+	for i := range m.Languages {
+		r := &m.Languages[i]
+		for _, id := range append([]string{r.Modern, r.Legacy}, r.Polygon...) {
+			if id != "" {
+				setFirst(m.languages, id, r)
+			}
+		}
+		for _, ext := range r.Extensions {
+			setFirst(m.extensions, ext, r.Modern)
+		}
+	}
+}
+
+func (m *Mapping) SolutionTag(name string) (SolutionTag, error) {
+	tag, ok := m.tags[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrBadSolutionTag, name)
+	}
+
+	return tag, nil
+}
+
+// Reverse of SolutionTag, used by exporters.
+func (m *Mapping) TagName(tag SolutionTag) (string, error) {
+	name, ok := m.tagNames[tag]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrBadSolutionTag, tag)
+	}
+
+	return name, nil
+}
+
+// Kattis submissions directory of problem.xml tag.
+func (m *Mapping) KattisVerdict(name string) (string, bool) {
+	verdict, ok := m.kattisExport[name]
+
+	return verdict, ok
+}
+
+// Reverse of KattisVerdict.
+func (m *Mapping) KattisTag(verdict string) (string, bool) {
+	name, ok := m.kattisImport[verdict]
+
+	return name, ok
+}
+
+// Source type of the file by extension, empty if unknown.
+func (m *Mapping) ExtensionType(name string) string {
+	return m.extensions[path.Ext(name)]
+}
+
+// Polygon rejects compiler ids unknown to the instance.
+func (m *Mapping) SourceType(typ string, isLegacy bool) string {
+	r, ok := m.languages[typ]
+	switch {
+	case !ok:
+		return typ
+	case isLegacy && r.Legacy != "":
+		return r.Legacy
+	case !isLegacy && r.Modern != "":
+		return r.Modern
+	}
+
+	return typ
+}
@@ -0,0 +1,97 @@
+package polygon_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapping(t *testing.T) {
+	t.Parallel()
+	m := polygon.DefaultMapping()
+
+	tag, err := m.SolutionTag("runtime-error")
+	require.NoError(t, err)
+	require.Equal(t, polygon.TagRuntimeError, tag)
+	_, err = m.SolutionTag("magic")
+	require.ErrorIs(t, err, polygon.ErrBadSolutionTag)
+	name, err := m.TagName(polygon.TagTLorOK)
+	require.NoError(t, err)
+	require.Equal(t, "time-limit-exceeded-or-accepted", name)
+
+	require.Equal(t, "cpp.g++17", m.ExtensionType("files/gen.cpp"))
+	require.Equal(t, "kotlin19", m.ExtensionType("sol.kt"))
+	require.Empty(t, m.ExtensionType("tests/01"))
+
+	verdict, ok := m.KattisVerdict("main")
+	require.True(t, ok)
+	require.Equal(t, "accepted", verdict)
+	name, ok = m.KattisTag("wrong_answer")
+	require.True(t, ok)
+	require.Equal(t, "wrong-answer", name)
+	_, ok = m.KattisVerdict("time-limit-exceeded-or-accepted")
+	require.False(t, ok)
+}
+
+func TestSourceType(t *testing.T) {
+	t.Parallel()
+	m := polygon.DefaultMapping()
+	for _, tc := range []struct {
+		typ    string
+		modern string
+		legacy string
+	}{
+		{"c.gcc", "c.gcc", "c.gcc"},
+		{"cpp.g++", "cpp.g++17", "cpp.g++"},
+		{"cpp.g++11", "cpp.g++17", "cpp.g++11"},
+		{"cpp.g++17", "cpp.g++17", "cpp.g++17"},
+		{"cpp.gcc11-64-winlibs-g++20", "cpp.gcc13-64-winlibs-g++20", "cpp.g++17"},
+		{"cpp.gcc13-64-winlibs-g++20", "cpp.gcc13-64-winlibs-g++20", "cpp.g++17"},
+		{"cpp.msys2-mingw64-9-g++17", "cpp.gcc14-64-msys2-g++23", "cpp.msys2-mingw64-9-g++17"},
+		{"cpp.gcc14-64-msys2-g++23", "cpp.gcc14-64-msys2-g++23", "cpp.g++17"},
+		{"java7", "java8", "java7"},
+		{"java8", "java8", "java8"},
+		{"java11", "java21", "java11"},
+		{"java21", "java21", "java11"},
+		{"kotlin", "kotlin", "kotlin"},
+		{"kotlin16", "kotlin16", "kotlin"},
+		{"kotlin17", "kotlin17", "kotlin"},
+		{"kotlin19", "kotlin19", "kotlin"},
+		{"python.pypy3", "python.pypy3-64", "python.pypy3"},
+		{"python.pypy3-64", "python.pypy3-64", "python.pypy3"},
+		{"rust", "rust", "rust"},
+	} {
+		require.Equal(t, tc.modern, m.SourceType(tc.typ, false), tc.typ)
+		require.Equal(t, tc.legacy, m.SourceType(tc.typ, true), tc.typ)
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	m, err := polygon.LoadMapping(filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	require.Equal(t, polygon.DefaultMapping(), m)
+
+	path := filepath.Join(dir, "mapping.json")
+	data := `{
+		"tags": [{"name": "failed", "polygon": "RJ"}],
+		"languages": [{"polygon": ["cpp.g++17"], "modern": "cpp.g++20", "legacy": "cpp.g++17", "extensions": [".cpp"]}]
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+	m, err = polygon.LoadMapping(path)
+	require.NoError(t, err)
+	tag, err := m.SolutionTag("failed")
+	require.NoError(t, err)
+	require.Equal(t, polygon.TagIncorrect, tag)
+	require.Equal(t, "cpp.g++20", m.SourceType("cpp.g++17", false))
+	require.Equal(t, "cpp.g++20", m.ExtensionType("sol.cpp"))
+	name, err := m.TagName(polygon.TagIncorrect)
+	require.NoError(t, err)
+	require.Equal(t, "failed", name)
+	require.Equal(t, "java21", m.SourceType("java11", false)) // defaults are kept
+}
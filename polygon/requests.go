@@ -64,6 +64,12 @@ func (tr TestRequest) UseInStatements(f bool) TestRequest {
 	return tr
 }
 
+func (tr TestRequest) CheckExisting(f bool) TestRequest {
+	tr["checkExisting"] = []string{strconv.FormatBool(f)}
+
+	return tr
+}
+
 type ProblemRequest url.Values
 
 func NewProblemRequest(pID int) ProblemRequest {
@@ -113,71 +119,15 @@ func NewFileRequest(pID int, typ FileType, name, file string) FileRequest {
 	}
 }
 
-// Polygon bug.
-//
-//nolint:cyclop,nestif,goconst
-func fixSourceType(typ string, isLegacy bool) string {
-	if !isLegacy {
-		if typ == "cpp.g++" {
-			return "cpp.g++17"
-		}
-		if typ == "cpp.g++11" {
-			return "cpp.g++17"
-		}
-		if typ == "cpp.gcc11-64-winlibs-g++20" {
-			return "cpp.gcc13-64-winlibs-g++20"
-		}
-		if typ == "cpp.msys2-mingw64-9-g++17" {
-			return "cpp.gcc14-64-msys2-g++23"
-		}
-		if typ == "java11" {
-			return "java21"
-		}
-		if typ == "java7" {
-			return "java8"
-		}
-		if typ == "python.pypy3" {
-			return "python.pypy3-64"
-		}
-	} else {
-		// can doesn't compille(
-		if typ == "cpp.gcc11-64-winlibs-g++20" {
-			return "cpp.g++17"
-		}
-		if typ == "cpp.gcc13-64-winlibs-g++20" {
-			return "cpp.g++17"
-		}
-		if typ == "cpp.gcc14-64-msys2-g++23" {
-			return "cpp.g++17"
-		}
-		if typ == "java21" {
-			return "java11"
-		}
-		if typ == "kotlin16" {
-			return "kotlin"
-		}
-		if typ == "kotlin17" {
-			return "kotlin"
-		}
-		if typ == "kotlin19" {
-			return "kotlin"
-		}
-		if typ == "python.pypy3-64" {
-			return "python.pypy3"
-		}
-	}
-
-	return typ
-}
-
 func (fr FileRequest) CheckExisting(f bool) FileRequest {
 	fr["checkExisting"] = []string{strconv.FormatBool(f)}
 
 	return fr
 }
 
-func (fr FileRequest) SourceType(typ string, isLegacy bool) FileRequest {
-	fr["sourceType"] = []string{fixSourceType(typ, isLegacy)}
+// Use Mapping.SourceType to convert compiler id for the instance.
+func (fr FileRequest) SourceType(typ string) FileRequest {
+	fr["sourceType"] = []string{typ}
 
 	return fr
 }
@@ -201,8 +151,9 @@ func (sr SolutionRequest) CheckExisting(f bool) SolutionRequest {
 	return sr
 }
 
-func (sr SolutionRequest) SourceType(typ string, isLegacy bool) SolutionRequest {
-	sr["sourceType"] = []string{fixSourceType(typ, isLegacy)}
+// Use Mapping.SourceType to convert compiler id for the instance.
+func (sr SolutionRequest) SourceType(typ string) SolutionRequest {
+	sr["sourceType"] = []string{typ}
 
 	return sr
 }
@@ -0,0 +1,52 @@
+package polygon
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultRateLimit   = 5
+
+	backoffBase = 500 * time.Millisecond
+	backoffMax  = 30 * time.Second
+)
+
+// Exponential backoff with equal jitter.
+func backoff(retry int) time.Duration {
+	d := backoffMax
+	if retry < 16 {
+		d = min(backoffBase<<(retry-1), backoffMax)
+	}
+	half := d / 2
+
+	return half + rand.N(half+1) //nolint:gosec // jitter doesn't need crypto.
+}
+
+// Shared by all queries of one client, safe for concurrent use.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(rps float64) *limiter {
+	return &limiter{
+		interval: time.Duration(float64(time.Second) / rps),
+	}
+}
+
+func (l *limiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(time.Until(at))
+}
@@ -0,0 +1,238 @@
+package rys
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+)
+
+const (
+	fullScore = 100
+	pointsEps = 1e-3 // float32 sum of fractional points
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+const (
+	CodeMissingFile       = "missing-file"
+	CodeTestCount         = "test-count"
+	CodeGroupOrder        = "group-order"
+	CodePointsTotal       = "points-total"
+	CodeUnknownGroup      = "unknown-group"
+	CodeUnusedGroup       = "unused-group"
+	CodeUnknownDependency = "unknown-dependency"
+	CodeDependencyCycle   = "dependency-cycle"
+	CodeMainSolution      = "main-solution"
+)
+
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s\t%s\t%s", i.Severity, i.Code, i.Message)
+}
+
+type Rys struct {
+	fsys   fs.FS
+	prob   *vydra.ProblemXML
+	issues []Issue
+}
+
+// Package content is read from fsys, e.g. os.DirFS or zip.Reader.
+func NewRys(fsys fs.FS) *Rys {
+	return &Rys{fsys: fsys}
+}
+
+func (r *Rys) report(sev Severity, code, format string, args ...any) {
+	r.issues = append(r.issues, Issue{Severity: sev, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// The error is returned only if problem.xml can't be read.
+func (r *Rys) Lint() ([]Issue, error) {
+	prob, err := vydra.ReadProblemXML(r.fsys, "problem.xml")
+	if err != nil {
+		return nil, err
+	}
+	r.prob = prob
+	r.issues = nil
+
+	r.lintFiles()
+	r.lintSolutions()
+	for _, ts := range prob.Judging.TestSets {
+		r.lintTestSet(&ts)
+	}
+	if val := prob.Assets.Validators.Validator; val != nil {
+		r.lintAssetTests("validator", &val.TestSet, val.InputPattern())
+	}
+	if chk := prob.Assets.Checker; chk != nil {
+		input, output, answer := chk.TestPatterns()
+		r.lintAssetTests("checker", &chk.TestSet, input, output, answer)
+	}
+
+	return r.issues, nil
+}
+
+func (r *Rys) checkFile(name, what string) {
+	r.checkFileSeverity(SeverityError, name, what)
+}
+
+func (r *Rys) checkFileSeverity(sev Severity, name, what string) {
+	if _, err := fs.Stat(r.fsys, name); err != nil {
+		r.report(sev, CodeMissingFile, "%s %s: %v", what, name, err)
+	}
+}
+
+func (r *Rys) lintFiles() {
+	prob := r.prob
+	for _, res := range prob.Files.Resources.Files {
+		r.checkFile(res.Path, "resource")
+	}
+	for _, exe := range prob.Files.Executables.Executables {
+		r.checkFile(exe.Source.Path, "executable")
+	}
+	for _, sol := range prob.Assets.Solutions.Solutions {
+		r.checkFile(sol.Source.Path, "solution")
+	}
+	for _, st := range prob.Statements.Statements { // vydra uploads statement-sections
+		r.checkFileSeverity(SeverityWarning, st.Path, "statement")
+	}
+	if val := prob.Assets.Validators.Validator; val != nil {
+		r.checkFile(val.Source.Path, "validator")
+	}
+	if chk := prob.Assets.Checker; chk != nil && chk.Source != nil {
+		r.checkFile(chk.Source.Path, "checker")
+	}
+	if inter := prob.Assets.Interactor; inter != nil {
+		r.checkFile(inter.Source.Path, "interactor")
+	}
+}
+
+func (r *Rys) lintSolutions() {
+	mains := 0
+	for _, sol := range r.prob.Assets.Solutions.Solutions {
+		if sol.Tag == "main" {
+			mains++
+		}
+	}
+	if mains != 1 {
+		r.report(SeverityError, CodeMainSolution, "exactly one main solution is required, found %d", mains)
+	}
+}
+
+func (r *Rys) lintTestCount(name string, ts *vydra.TestSet) {
+	if len(ts.Tests.Tests) != ts.TestCount {
+		r.report(SeverityError, CodeTestCount, "%s: %d tests, test-count %d", name, len(ts.Tests.Tests), ts.TestCount)
+	}
+}
+
+// Patterns are the same as vydra uses, Polygon defaults included.
+func (r *Rys) lintAssetTests(name string, ts *vydra.TestSet, patterns ...string) {
+	r.lintTestCount(name+" tests", ts)
+	for idx := range ts.Tests.Tests {
+		for _, pattern := range patterns {
+			r.checkFile(fmt.Sprintf(pattern, idx+1), name+" test")
+		}
+	}
+}
+
+func (r *Rys) lintTestSet(ts *vydra.TestSet) {
+	r.lintTestCount("testset "+ts.Name, ts)
+	pattern := ts.InputPattern()
+	var total float32
+	firstIdx := make(map[string]int)
+	lastIdx := make(map[string]int)
+	count := make(map[string]int)
+	for idx, test := range ts.Tests.Tests {
+		if test.Method != "generated" { // generated inputs are optional
+			r.checkFile(fmt.Sprintf(pattern, idx+1), "test")
+		}
+		total += test.Points
+		if test.Group == "" {
+			continue
+		}
+		if _, ok := firstIdx[test.Group]; !ok {
+			firstIdx[test.Group] = idx + 1
+		}
+		lastIdx[test.Group] = idx + 1
+		count[test.Group]++
+	}
+	if total != 0 && math.Abs(float64(total)-fullScore) > pointsEps && ts.Name == polygon.DefaultTestset {
+		r.report(SeverityWarning, CodePointsTotal, "testset %s: points sum is %g, not %d", ts.Name, total, fullScore)
+	}
+
+	declared := make(map[string][]string)
+	for _, g := range ts.Groups.Groups {
+		deps := make([]string, 0, len(g.Dependencies.Dependencies))
+		for _, dep := range g.Dependencies.Dependencies {
+			deps = append(deps, dep.Group)
+		}
+		declared[g.Name] = deps
+		if count[g.Name] == 0 {
+			r.report(SeverityWarning, CodeUnusedGroup, "testset %s: group %s has no tests", ts.Name, g.Name)
+		} else if lastIdx[g.Name]-firstIdx[g.Name]+1 != count[g.Name] {
+			r.report(SeverityWarning, CodeGroupOrder, "testset %s: group %s tests are not contiguous", ts.Name, g.Name)
+		}
+	}
+	reported := make(map[string]bool)
+	for _, test := range ts.Tests.Tests {
+		if _, ok := declared[test.Group]; test.Group != "" && !ok && !reported[test.Group] {
+			r.report(SeverityError, CodeUnknownGroup, "testset %s: group %s is not declared", ts.Name, test.Group)
+			reported[test.Group] = true
+		}
+	}
+	r.lintDependencies(ts, declared)
+}
+
+// Dependencies should form a DAG, every back edge found by DFS is reported as a cycle.
+func (r *Rys) lintDependencies(ts *vydra.TestSet, deps map[string][]string) {
+	const (
+		white = iota
+		gray
+		black
+	)
+	for _, g := range ts.Groups.Groups {
+		for _, dep := range deps[g.Name] {
+			if _, ok := deps[dep]; !ok {
+				r.report(SeverityError, CodeUnknownDependency,
+					"testset %s: group %s depends on unknown group %s", ts.Name, g.Name, dep)
+			}
+		}
+	}
+	color := make(map[string]int)
+	var stack []string
+	var dfs func(g string)
+	dfs = func(g string) {
+		color[g] = gray
+		stack = append(stack, g)
+		for _, dep := range deps[g] {
+			switch color[dep] {
+			case gray:
+				cycle := append(slices.Clone(stack[slices.Index(stack, dep):]), dep)
+				r.report(SeverityError, CodeDependencyCycle,
+					"testset %s: dependency cycle %s", ts.Name, strings.Join(cycle, " -> "))
+			case white:
+				dfs(dep)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		color[g] = black
+	}
+	for _, g := range ts.Groups.Groups {
+		if color[g.Name] == white {
+			dfs(g.Name)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package rys_test
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Gornak40/algolymp/polygon/rys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+	issues, err := rys.NewRys(os.DirFS("testdata/bad")).Lint()
+	require.NoError(t, err)
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	require.Equal(t, []string{
+		"error\tmissing-file\tsolution solutions/c.cpp: stat solutions/c.cpp: no such file or directory",
+		"error\tmain-solution\texactly one main solution is required, found 2",
+		"error\ttest-count\ttestset tests: 5 tests, test-count 6",
+		"error\tmissing-file\ttest tests/05: stat tests/05: no such file or directory",
+		"warning\tpoints-total\ttestset tests: points sum is 90, not 100",
+		"warning\tgroup-order\ttestset tests: group 1 tests are not contiguous",
+		"warning\tunused-group\ttestset tests: group 5 has no tests",
+		"error\tunknown-group\ttestset tests: group 3 is not declared",
+		"error\tunknown-dependency\ttestset tests: group 2 depends on unknown group 4",
+		"error\tunknown-dependency\ttestset tests: group 5 depends on unknown group 3",
+		"error\tdependency-cycle\ttestset tests: dependency cycle 1 -> 2 -> 1",
+		"error\tdependency-cycle\ttestset tests: dependency cycle 5 -> 5",
+	}, lines)
+
+	_, err = rys.NewRys(os.DirFS(t.TempDir())).Lint()
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLintDefaults(t *testing.T) {
+	t.Parallel()
+	file := func(data string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(data)} }
+	issues, err := rys.NewRys(fstest.MapFS{
+		// float32 points sum is 99.99999
+		"problem.xml": file(`<problem>
+    <judging>
+        <testset name="tests">
+            <test-count>6</test-count>
+            <tests>
+                <test method="manual" points="16.7"/>
+                <test method="manual" points="16.7"/>
+                <test method="manual" points="16.6"/>
+                <test method="generated" points="16.7"/>
+                <test method="generated" points="16.7"/>
+                <test method="generated" points="16.6"/>
+            </tests>
+        </testset>
+    </judging>
+    <assets>
+        <checker type="testlib">
+            <source path="files/check.cpp" type="cpp.g++17"/>
+            <testset>
+                <test-count>1</test-count>
+                <tests><test verdict="ok"/></tests>
+            </testset>
+        </checker>
+        <validators>
+            <validator>
+                <source path="files/val.cpp" type="cpp.g++17"/>
+                <testset>
+                    <test-count>1</test-count>
+                    <tests><test verdict="valid"/></tests>
+                </testset>
+            </validator>
+        </validators>
+        <solutions>
+            <solution tag="main"><source path="solutions/main.cpp" type="cpp.g++17"/></solution>
+        </solutions>
+    </assets>
+</problem>`),
+		"tests/01":                       file("1\n"),
+		"tests/02":                       file("2\n"),
+		"tests/03":                       file("3\n"),
+		"files/check.cpp":                file(""),
+		"files/val.cpp":                  file(""),
+		"solutions/main.cpp":             file(""),
+		"files/tests/validator-tests/01": file("1\n"),
+		"files/tests/checker-tests/01":   file("1\n"),
+		"files/tests/checker-tests/01.a": file("1\n"),
+	}).Lint()
+	require.NoError(t, err)
+	require.Equal(t, []rys.Issue{{
+		Severity: rys.SeverityError,
+		Code:     rys.CodeMissingFile,
+		Message:  "checker test files/tests/checker-tests/01.o: open files/tests/checker-tests/01.o: file does not exist",
+	}}, issues)
+}
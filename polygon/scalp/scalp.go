@@ -0,0 +1,159 @@
+package scalp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	StrategyIncremental = "incremental"
+	StrategyEqual       = "equal"
+	StrategyWeighted    = "weighted"
+	StrategyGroups      = "groups"
+)
+
+var (
+	ErrAllTestsAreSamples = polygon.ErrAllTestsAreSamples
+	ErrUnknownStrategy    = errors.New("unknown strategy")
+	ErrBadSpec            = errors.New("bad spec")
+	ErrBadTotal           = errors.New("bad total score")
+	ErrEmptyGroup         = errors.New("group has no scored tests")
+)
+
+// Test points, empty group means the test group is not changed.
+type Assignment struct {
+	Index  int
+	Group  string
+	Points int
+}
+
+type Scalp struct {
+	client  *polygon.Polygon
+	pID     int
+	total   int
+	samples bool
+}
+
+// Points of all tests sum up to total, samples get 0 points unless samples is set.
+func NewScalp(client *polygon.Polygon, pID, total int, samples bool) *Scalp {
+	return &Scalp{
+		client:  client,
+		pID:     pID,
+		total:   total,
+		samples: samples,
+	}
+}
+
+// Build test points by strategy, nothing is saved.
+func (s *Scalp) Plan(strategy, spec string) ([]Assignment, error) {
+	if s.total <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrBadTotal, s.total)
+	}
+	tests, err := s.client.GetTests(s.pID, polygon.DefaultTestset)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tests, func(i, j int) bool { return tests[i].Index < tests[j].Index })
+	switch strategy {
+	case StrategyIncremental:
+		return s.incremental(tests)
+	case StrategyEqual:
+		return s.equal(tests)
+	case StrategyWeighted:
+		return s.weighted(tests, spec)
+	case StrategyGroups:
+		return s.groups(tests, spec)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, strategy)
+}
+
+// Save test points (and groups if any) into Polygon.
+func (s *Scalp) Apply(plan []Assignment) error {
+	if err := s.client.EnablePoints(s.pID); err != nil {
+		return err
+	}
+	for _, a := range plan {
+		if a.Group != "" {
+			if err := s.client.EnableGroups(s.pID, polygon.DefaultTestset); err != nil {
+				return err
+			}
+
+			break
+		}
+	}
+	for _, a := range plan {
+		rt := polygon.NewTestRequest(s.pID, a.Index).Points(float32(a.Points))
+		if a.Group != "" {
+			rt = rt.Group(a.Group)
+		}
+		if err := s.client.SaveTest(rt); err != nil {
+			return err
+		}
+	}
+	logrus.WithField("tests", len(plan)).Info("scoring saved")
+
+	return nil
+}
+
+func WritePlan(w io.Writer, plan []Assignment) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // padding
+	fmt.Fprintln(tw, "test\tgroup\tpoints")
+	total := 0
+	for _, a := range plan {
+		group := a.Group
+		if group == "" {
+			group = "-"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\n", a.Index, group, a.Points)
+		total += a.Points
+	}
+	fmt.Fprintf(tw, "total\t\t%d\n", total)
+
+	return tw.Flush()
+}
+
+func (s *Scalp) scored(test *polygon.TestAnswer) bool {
+	return !test.UseInStatements || s.samples
+}
+
+// Points proportional to weights by the largest remainder method,
+// ties are resolved in favor of the later tests.
+func distribute(total int, weights []int) ([]int, error) {
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		return nil, fmt.Errorf("%w: all weights are zero", ErrBadSpec)
+	}
+	res := make([]int, len(weights))
+	rest := total
+	for i, w := range weights {
+		res[i] = total * w / sum
+		rest -= res[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		ri, rj := total*weights[order[i]]%sum, total*weights[order[j]]%sum
+		if ri != rj {
+			return ri > rj
+		}
+
+		return order[i] > order[j]
+	})
+	for _, i := range order[:rest] {
+		res[i]++
+	}
+
+	return res, nil
+}
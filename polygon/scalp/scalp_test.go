@@ -0,0 +1,99 @@
+package scalp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/scalp"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareProblem(t *testing.T, srv *fakepolygon.Server, count int, groups []string) *polygon.Polygon {
+	t.Helper()
+	srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+	if groups != nil {
+		require.NoError(t, pc.EnableGroups(42, polygon.DefaultTestset))
+	}
+	for i := 1; i <= count; i++ {
+		tr := polygon.NewTestRequest(42, i).Input("1 2").UseInStatements(i <= 2)
+		if groups != nil {
+			tr = tr.Group(groups[i-1])
+		}
+		require.NoError(t, pc.SaveTest(tr))
+	}
+
+	return pc
+}
+
+func points(plan []scalp.Assignment) []int {
+	res := make([]int, 0, len(plan))
+	for _, a := range plan {
+		res = append(res, a.Points)
+	}
+
+	return res
+}
+
+func TestIncrementalScoring(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	pc := prepareProblem(t, srv, 9, nil)
+
+	sc := scalp.NewScalp(pc, 42, polygon.FullProblemScore, false)
+	plan, err := sc.Plan(scalp.StrategyIncremental, "")
+	require.NoError(t, err)
+	require.NoError(t, sc.Apply(plan))
+	tests := srv.Problem(42).Tests[polygon.DefaultTestset]
+	groups := []string{"0", "0", "1", "1", "1", "1", "1", "2", "2"}
+	points := []float32{0, 0, 14, 14, 14, 14, 14, 15, 15}
+	for i := range groups {
+		require.Equal(t, groups[i], tests[i+1].Group)
+		require.InDelta(t, points[i], tests[i+1].Points, 0)
+	}
+
+	_, err = scalp.NewScalp(pc, 43, polygon.FullProblemScore, false).Plan(scalp.StrategyIncremental, "")
+	require.ErrorIs(t, err, polygon.ErrBadPolygonStatus)
+
+	pc = prepareProblem(t, fakepolygon.NewServer(t), 2, nil)
+	_, err = scalp.NewScalp(pc, 42, polygon.FullProblemScore, false).Plan(scalp.StrategyIncremental, "")
+	require.ErrorIs(t, err, polygon.ErrAllTestsAreSamples)
+}
+
+func TestStrategies(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	pc := prepareProblem(t, srv, 6, []string{"0", "0", "1", "1", "2", "2"})
+	prepared := len(srv.Calls())
+
+	plan, err := scalp.NewScalp(pc, 42, 10, false).Plan(scalp.StrategyEqual, "")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 0, 2, 2, 3, 3}, points(plan))
+	require.Empty(t, plan[2].Group)
+
+	plan, err = scalp.NewScalp(pc, 42, 50, true).Plan(scalp.StrategyWeighted, "1-4:1,5-6:3")
+	require.NoError(t, err)
+	require.Equal(t, []int{5, 5, 5, 5, 15, 15}, points(plan))
+
+	sc := scalp.NewScalp(pc, 42, polygon.FullProblemScore, false)
+	plan, err = sc.Plan(scalp.StrategyGroups, "0:0,1:35,2:65")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 0, 17, 18, 32, 33}, points(plan))
+
+	var sb strings.Builder
+	require.NoError(t, scalp.WritePlan(&sb, plan))
+	require.True(t, strings.HasPrefix(sb.String(), "test   group  points\n1      -      0\n"))
+	require.True(t, strings.HasSuffix(sb.String(), "total         100\n"))
+	require.NotContains(t, srv.Calls()[prepared:], "problem.saveTest") // preview only
+
+	_, err = sc.Plan(scalp.StrategyGroups, "1:35,2:60")
+	require.ErrorIs(t, err, scalp.ErrBadTotal)
+	_, err = sc.Plan(scalp.StrategyGroups, "0:10,1:30,2:60")
+	require.ErrorIs(t, err, scalp.ErrEmptyGroup)
+	_, err = sc.Plan(scalp.StrategyWeighted, "4-1:2")
+	require.ErrorIs(t, err, scalp.ErrBadSpec)
+	_, err = sc.Plan("random", "")
+	require.ErrorIs(t, err, scalp.ErrUnknownStrategy)
+}
@@ -0,0 +1,176 @@
+package scalp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/sirupsen/logrus"
+)
+
+// Groups "0" (samples), "1" and "2": 0 0 5 5 ... 5 6 6 ... 6.
+func (s *Scalp) incremental(tests []polygon.TestAnswer) ([]Assignment, error) {
+	testsCount := 0
+	for i := range tests {
+		if s.scored(&tests[i]) {
+			testsCount++
+		}
+	}
+	if testsCount == 0 {
+		return nil, ErrAllTestsAreSamples
+	}
+	small := s.total / testsCount
+	smallCnt := testsCount - (s.total - small*testsCount)
+	logrus.WithFields(logrus.Fields{
+		"zeroCount":  len(tests) - testsCount,
+		"smallScore": small,
+		"smallCount": smallCnt,
+		"bigScore":   small + 1,
+		"bigCount":   testsCount - smallCnt,
+	}).Info("points statistics")
+	plan := make([]Assignment, 0, len(tests))
+	for i := range tests {
+		a := Assignment{Index: tests[i].Index}
+		switch {
+		case smallCnt == 0:
+			a.Group = "2"
+			a.Points = small + 1
+		case s.scored(&tests[i]):
+			a.Group = "1"
+			a.Points = small
+			smallCnt--
+		default:
+			a.Group = "0"
+		}
+		plan = append(plan, a)
+	}
+
+	return plan, nil
+}
+
+// Equal points without groups, the remainder goes to the last tests.
+func (s *Scalp) equal(tests []polygon.TestAnswer) ([]Assignment, error) {
+	weights := make([]int, len(tests))
+	for i := range tests {
+		if s.scored(&tests[i]) {
+			weights[i] = 1
+		}
+	}
+
+	return s.byWeights(tests, weights, ErrAllTestsAreSamples)
+}
+
+// Spec "1-5:1,6-10:2" sets weights by test index ranges, other tests get 0 points.
+func (s *Scalp) weighted(tests []polygon.TestAnswer, spec string) ([]Assignment, error) {
+	items, err := parseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	weights := make([]int, len(tests))
+	for _, item := range items {
+		first, last, err := parseRange(item.key)
+		if err != nil {
+			return nil, err
+		}
+		for i := range tests {
+			if idx := tests[i].Index; idx >= first && idx <= last && s.scored(&tests[i]) {
+				weights[i] = item.value
+			}
+		}
+	}
+
+	return s.byWeights(tests, weights, ErrBadSpec)
+}
+
+func (s *Scalp) byWeights(tests []polygon.TestAnswer, weights []int, errZero error) ([]Assignment, error) {
+	points, err := distribute(s.total, weights)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no scored tests", errZero)
+	}
+	plan := make([]Assignment, 0, len(tests))
+	for i := range tests {
+		plan = append(plan, Assignment{Index: tests[i].Index, Points: points[i]})
+	}
+
+	return plan, nil
+}
+
+// Spec "1:20,2:30,3:50" sets total points of existing groups, split equally over their tests.
+func (s *Scalp) groups(tests []polygon.TestAnswer, spec string) ([]Assignment, error) {
+	items, err := parseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	sum := 0
+	for _, item := range items {
+		sum += item.value
+	}
+	if sum != s.total {
+		return nil, fmt.Errorf("%w: spec sum is %d, not %d", ErrBadTotal, sum, s.total)
+	}
+	plan := make([]Assignment, len(tests))
+	for i := range tests {
+		plan[i].Index = tests[i].Index
+	}
+	for _, item := range items {
+		if item.value == 0 {
+			continue
+		}
+		weights := make([]int, len(tests))
+		for i := range tests {
+			if tests[i].Group == item.key && s.scored(&tests[i]) {
+				weights[i] = 1
+			}
+		}
+		points, err := distribute(item.value, weights)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrEmptyGroup, item.key)
+		}
+		for i := range plan {
+			plan[i].Points += points[i]
+		}
+	}
+
+	return plan, nil
+}
+
+type specItem struct {
+	key   string
+	value int
+}
+
+func parseSpec(spec string) ([]specItem, error) {
+	var items []specItem
+	for _, part := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %q", ErrBadSpec, part)
+		}
+		num, err := strconv.Atoi(value)
+		if err != nil || num < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrBadSpec, part)
+		}
+		items = append(items, specItem{key: key, value: num})
+	}
+
+	return items, nil
+}
+
+// Range "3-7" or a single test "3".
+func parseRange(s string) (int, int, error) {
+	left, right, ok := strings.Cut(s, "-")
+	if !ok {
+		right = left
+	}
+	first, err := strconv.Atoi(left)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: range %q", ErrBadSpec, s)
+	}
+	last, err := strconv.Atoi(right)
+	if err != nil || first > last {
+		return 0, 0, fmt.Errorf("%w: range %q", ErrBadSpec, s)
+	}
+
+	return first, last, nil
+}
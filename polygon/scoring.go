@@ -11,18 +11,22 @@ const (
 )
 
 var (
+	// The same as scalp.ErrAllTestsAreSamples, kept for IncrementalScoring.
 	ErrAllTestsAreSamples = errors.New("all tests are samples, try -s flag")
-	ErrBadTestsOrder      = errors.New("bad tests order, fix in polygon required")
+	// Deprecated: valeria supports non-contiguous groups, the error is not returned anymore.
+	ErrBadTestsOrder = errors.New("bad tests order, fix in polygon required")
 )
 
+// Deprecated: use scalp Plan with scalp.StrategyIncremental and Apply,
+// the result is the same for the full score.
 func (p *Polygon) IncrementalScoring(pID int, samples bool) error {
 	if err := p.EnablePoints(pID); err != nil {
 		return err
 	}
-	if err := p.EnableGroups(pID); err != nil {
+	if err := p.EnableGroups(pID, DefaultTestset); err != nil {
 		return err
 	}
-	tests, err := p.GetTests(pID)
+	tests, err := p.GetTests(pID, DefaultTestset)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,31 @@
+package polygon_test
+
+import (
+	"testing"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrementalScoring(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+	for i := 1; i <= 9; i++ {
+		tr := polygon.NewTestRequest(42, i).Input("1 2").UseInStatements(i <= 2)
+		require.NoError(t, pc.SaveTest(tr))
+	}
+
+	require.NoError(t, pc.IncrementalScoring(42, false)) //nolint:staticcheck // deprecated method is still supported
+	tests := prob.Tests[polygon.DefaultTestset]
+	groups := []string{"0", "0", "1", "1", "1", "1", "1", "2", "2"}
+	points := []float32{0, 0, 14, 14, 14, 14, 14, 15, 15}
+	for i := range groups {
+		require.Equal(t, groups[i], tests[i+1].Group)
+		require.InDelta(t, points[i], tests[i+1].Points, 0)
+	}
+
+	require.ErrorIs(t, pc.IncrementalScoring(43, false), polygon.ErrBadPolygonStatus) //nolint:staticcheck // the same
+}
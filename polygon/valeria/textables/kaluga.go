@@ -7,6 +7,7 @@ import (
 
 // Works both in HTML and PDF render.
 type KalugaTable struct {
+	Lang   Language
 	groups []string
 }
 
@@ -18,7 +19,7 @@ func (t *KalugaTable) AddGroup(info GroupInfo) {
 	case Group0:
 		return
 	case GroupLast:
-		limits = "Ограничения из условия"
+		limits = t.Lang.tr("Ограничения из условия")
 	case GroupRegular:
 	}
 	row := fmt.Sprintf("%s & %d & %s \\\\ \\hline",
@@ -34,9 +35,9 @@ func (t *KalugaTable) String() string {
 		"\\begin{center}",
 		"\\begin{tabular}{|c|c|c|}",
 		"\\hline",
-		"\\bf{Подзадача} &",
-		"\\bf{Баллы} &",
-		"\\bf{Ограничения}",
+		"\\bf{" + t.Lang.tr("Подзадача") + "} &",
+		"\\bf{" + t.Lang.tr("Баллы") + "} &",
+		"\\bf{" + t.Lang.tr("Ограничения") + "}",
 		"\\\\ \\hline",
 	}
 	table = append(table, t.groups...)
@@ -0,0 +1,63 @@
+package textables
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	TypeUniversal = "universal"
+	TypeMoscow    = "moscow"
+	TypeKaluga    = "kaluga"
+)
+
+// Polygon statement language, tables are in Russian by default.
+// Phrases without translation are kept in Russian.
+type Language string
+
+const (
+	Russian Language = "russian"
+	English Language = "english"
+)
+
+var ErrUnknownTexTable = errors.New("unknown textable")
+
+//nolint:gochecknoglobals // table phrases
+var english = map[string]string{
+	"тесты из условия":           "sample tests",
+	"Тесты из условия.":          "Sample tests.",
+	"Подзадача":                  "Subtask",
+	"Баллы":                      "Points",
+	"Дополнительные ограничения": "Additional constraints",
+	"Необходимые подзадачи":      "Required subtasks",
+	"Группа":                     "Group",
+	"Доп. ограничения":           "Add. constraints",
+	"Необх. группы":              "Req. groups",
+	"Комментарий":                "Comment",
+	"Ограничения":                "Constraints",
+	"Ограничения из условия":     "Original constraints",
+}
+
+func (l Language) tr(phrase string) string {
+	if tr, ok := english[phrase]; ok && l == English {
+		return tr
+	}
+
+	return phrase
+}
+
+func NewTable(typ string, lang Language, vars []string) (Table, error) {
+	switch typ {
+	case TypeUniversal:
+		return &UniversalTable{Lang: lang}, nil
+	case TypeMoscow:
+		t := NewMoscowTable(vars)
+		t.Lang = lang
+
+		return t, nil
+	case TypeKaluga:
+		return &KalugaTable{Lang: lang}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownTexTable, typ)
+}
@@ -8,6 +8,7 @@ import (
 // If len(vars) != 0 works only in PDF render.
 // Otherwise works both in HTML and PDF render.
 type MoscowTable struct {
+	Lang   Language
 	groups []string
 	vars   []string
 }
@@ -30,7 +31,7 @@ func NewMoscowTable(vars []string) *MoscowTable {
 func (t *MoscowTable) AddGroup(info GroupInfo) {
 	var comment, limits string
 	if info.Type == Group0 {
-		comment = "Тесты из условия."
+		comment = t.Lang.tr("Тесты из условия.")
 	}
 	if len(t.vars) > 0 {
 		limits = strings.Repeat(" & ", len(t.vars))
@@ -56,23 +57,23 @@ func (t *MoscowTable) String() string {
 		table = append(table,
 			fmt.Sprintf("\\begin{tabular}{|c|c|%sc|c|}", strings.Repeat("c|", len(t.vars))),
 			"\\hline",
-			fmt.Sprintf("& & \\multicolumn{%d}{c|}{Доп. ограничения} & & \\\\", len(t.vars)),
+			fmt.Sprintf("& & \\multicolumn{%d}{c|}{%s} & & \\\\", len(t.vars), t.Lang.tr("Доп. ограничения")),
 			fmt.Sprintf("\\cline{3-%d}", len(t.vars)+clineDelta),
-			"\\raisebox{2.25ex}[0cm][0cm]{Группа}",
-			"& \\raisebox{2.25ex}[0cm][0cm]{Баллы}",
+			"\\raisebox{2.25ex}[0cm][0cm]{"+t.Lang.tr("Группа")+"}",
+			"& \\raisebox{2.25ex}[0cm][0cm]{"+t.Lang.tr("Баллы")+"}",
 			"& "+strings.Join(t.vars, " & "),
-			"& \\raisebox{2.25ex}[0cm][0cm]{Необх. группы}",
-			"& \\raisebox{2.25ex}[0cm][0cm]{Комментарий}",
+			"& \\raisebox{2.25ex}[0cm][0cm]{"+t.Lang.tr("Необх. группы")+"}",
+			"& \\raisebox{2.25ex}[0cm][0cm]{"+t.Lang.tr("Комментарий")+"}",
 		)
 	} else {
 		table = append(table,
 			"\\begin{tabular}{|c|c|c|c|c|}",
 			"\\hline",
-			"Группа",
-			"& Баллы",
-			"& Доп. ограничения",
-			"& Необх. группы",
-			"& Комментарий",
+			t.Lang.tr("Группа"),
+			"& "+t.Lang.tr("Баллы"),
+			"& "+t.Lang.tr("Доп. ограничения"),
+			"& "+t.Lang.tr("Необх. группы"),
+			"& "+t.Lang.tr("Комментарий"),
 		)
 	}
 	table = append(table, "\\\\", "\\hline")
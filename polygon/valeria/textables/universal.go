@@ -7,6 +7,7 @@ import (
 
 // Works both in HTML and PDF render.
 type UniversalTable struct {
+	Lang   Language
 	groups []string
 }
 
@@ -16,7 +17,7 @@ func (t *UniversalTable) AddGroup(info GroupInfo) {
 	var limits string
 	switch info.Type {
 	case Group0:
-		limits = "тесты из условия"
+		limits = t.Lang.tr("тесты из условия")
 	case GroupLast:
 		limits = "---"
 	case GroupRegular:
@@ -35,10 +36,10 @@ func (t *UniversalTable) String() string {
 		"\\begin{center}",
 		"\\begin{tabular}{|c|c|c|c|}",
 		"\\hline",
-		"\\textbf{Подзадача} &",
-		"\\textbf{Баллы} &",
-		"\\textbf{Дополнительные ограничения} &",
-		"\\textbf{Необходимые подзадачи}",
+		"\\textbf{" + t.Lang.tr("Подзадача") + "} &",
+		"\\textbf{" + t.Lang.tr("Баллы") + "} &",
+		"\\textbf{" + t.Lang.tr("Дополнительные ограничения") + "} &",
+		"\\textbf{" + t.Lang.tr("Необходимые подзадачи") + "}",
 		"\\\\ \\hline",
 	}
 	table = append(table, t.groups...)
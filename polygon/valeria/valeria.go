@@ -3,6 +3,8 @@ package valeria
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/Gornak40/algolymp/polygon"
@@ -14,10 +16,12 @@ var (
 	ErrUnknownPointsPolicy = errors.New("unknown points policy")
 	ErrMissedGroup         = errors.New("missed group, no tests")
 	ErrBadTestScore        = errors.New("different test_score in one group")
+	ErrNoStatement         = errors.New("no statement in language")
 )
 
 type Valeria struct {
-	client *polygon.Polygon
+	client     *polygon.Polygon
+	statements map[string]textables.Table
 }
 
 func NewValeria(client *polygon.Polygon) *Valeria {
@@ -36,10 +40,8 @@ const (
 type group struct {
 	name         string
 	score        int
-	count        int
 	policy       pointsPolicy
-	firstIdx     int
-	lastIdx      int
+	tests        []int // sorted indices, not necessarily contiguous
 	minScore     int
 	maxScore     int
 	dependencies []string
@@ -57,17 +59,13 @@ func newScoring(tests []polygon.TestAnswer, groups []polygon.GroupAnswer) (*scor
 			grMapa[test.Group] = group{
 				name:     test.Group,
 				score:    score,
-				count:    1,
-				firstIdx: test.Index,
-				lastIdx:  test.Index,
+				tests:    []int{test.Index},
 				minScore: score,
 				maxScore: score,
 			}
 		} else {
 			g.score += score
-			g.count++
-			g.firstIdx = min(g.firstIdx, test.Index)
-			g.lastIdx = max(g.lastIdx, test.Index)
+			g.tests = append(g.tests, test.Index)
 			g.minScore = min(g.minScore, score)
 			g.maxScore = max(g.maxScore, score)
 			grMapa[test.Group] = g
@@ -81,9 +79,7 @@ func newScoring(tests []polygon.TestAnswer, groups []polygon.GroupAnswer) (*scor
 		if !ok {
 			return nil, fmt.Errorf("%w: group %s", ErrMissedGroup, group.Name)
 		}
-		if g.lastIdx-g.firstIdx+1 != g.count {
-			return nil, fmt.Errorf("%w: group %s", polygon.ErrBadTestsOrder, group.Name)
-		}
+		slices.Sort(g.tests)
 		switch group.PointsPolicy {
 		case "COMPLETE_GROUP":
 			g.policy = policyCompleteGroup
@@ -102,11 +98,41 @@ func newScoring(tests []polygon.TestAnswer, groups []polygon.GroupAnswer) (*scor
 	return &scorer, nil
 }
 
+// Scoring group as seen by other problem formats, e.g. CMS subtasks.
+type GroupScore struct {
+	Name         string
+	Score        int
+	TestScore    int // the same for all tests of each test group
+	EachTest     bool
+	Tests        []int // sorted indices
+	Dependencies []string
+}
+
+func GroupScores(tests []polygon.TestAnswer, groups []polygon.GroupAnswer) ([]GroupScore, error) {
+	scorer, err := newScoring(tests, groups)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]GroupScore, 0, len(scorer.groups))
+	for _, g := range scorer.groups {
+		res = append(res, GroupScore{
+			Name:         g.name,
+			Score:        g.score,
+			TestScore:    g.minScore,
+			EachTest:     g.policy == policyEachTest,
+			Tests:        g.tests,
+			Dependencies: g.dependencies,
+		})
+	}
+
+	return res, nil
+}
+
 func (s *scoring) buildValuer() string {
 	res := make([]string, 0, len(s.groups))
 	for _, g := range s.groups {
-		cur := fmt.Sprintf("group %s {\n\ttests %d-%d;\n\t",
-			g.name, g.firstIdx, g.lastIdx)
+		cur := fmt.Sprintf("group %s {\n\ttests %s;\n\t",
+			g.name, strings.Join(testRanges(g.tests), ", "))
 		switch g.policy {
 		case policyCompleteGroup:
 			cur += fmt.Sprintf("score %d;\n", g.score)
@@ -123,6 +149,25 @@ func (s *scoring) buildValuer() string {
 	return strings.Join(res, "\n")
 }
 
+// Sorted indices into "a-b" ranges of consecutive tests, a single test is written as "a".
+func testRanges(tests []int) []string {
+	var res []string
+	for i := 0; i < len(tests); {
+		j := i
+		for j+1 < len(tests) && tests[j+1] == tests[j]+1 {
+			j++
+		}
+		if i == j {
+			res = append(res, strconv.Itoa(tests[i]))
+		} else {
+			res = append(res, fmt.Sprintf("%d-%d", tests[i], tests[j]))
+		}
+		i = j + 1
+	}
+
+	return res
+}
+
 func (s *scoring) buildScoring(table textables.Table) {
 	for index, g := range s.groups {
 		info := textables.GroupInfo{
@@ -142,12 +187,22 @@ func (s *scoring) buildScoring(table textables.Table) {
 	}
 }
 
+// Tables by statement language, their scoring sections are replaced by InformaticsValuer.
+// Other statement sections are not changed.
+func (v *Valeria) UseStatements(tables map[string]textables.Table) {
+	v.statements = tables
+}
+
 func (v *Valeria) InformaticsValuer(pID int, table textables.Table, verbose bool) error {
-	groups, err := v.client.GetGroups(pID)
+	langs, err := v.statementLangs(pID)
 	if err != nil {
 		return err
 	}
-	tests, err := v.client.GetTests(pID)
+	groups, err := v.client.GetGroups(pID, polygon.DefaultTestset)
+	if err != nil {
+		return err
+	}
+	tests, err := v.client.GetTests(pID, polygon.DefaultTestset)
 	if err != nil {
 		return err
 	}
@@ -165,6 +220,36 @@ func (v *Valeria) InformaticsValuer(pID int, table textables.Table, verbose bool
 		return err
 	}
 	scorer.buildScoring(table)
+	for _, lang := range langs {
+		table := v.statements[lang]
+		scorer.buildScoring(table)
+		logrus.WithField("language", lang).Info("save statement scoring")
+		sr := polygon.NewStatementRequest(pID, lang).Scoring(table.String())
+		if err := v.client.SaveStatement(sr); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
+
+// Sorted statement languages of tables, all of them must exist in the problem.
+func (v *Valeria) statementLangs(pID int) ([]string, error) {
+	if len(v.statements) == 0 {
+		return nil, nil
+	}
+	statements, err := v.client.GetStatements(pID)
+	if err != nil {
+		return nil, err
+	}
+	langs := make([]string, 0, len(v.statements))
+	for lang := range v.statements {
+		if _, ok := statements[lang]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNoStatement, lang)
+		}
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+
+	return langs, nil
+}
@@ -0,0 +1,107 @@
+package valeria_test
+
+import (
+	"testing"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/valeria"
+	"github.com/Gornak40/algolymp/polygon/valeria/textables"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInformaticsValuer(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddScoredProblem(42, "aplusb",
+		[]string{"0", "0", "1", "1", "2", "2", "2"},
+		[]float32{0, 0, 20, 20, 20, 20, 20}, 0,
+	)
+	pc := polygon.NewPolygon(srv.Config())
+	require.NoError(t, pc.SaveTestGroup(polygon.NewTestGroupRequest(42, polygon.DefaultTestset, "0").
+		PointsPolicy(polygon.PolicyEachTest)))
+	require.NoError(t, pc.SaveTestGroup(polygon.NewTestGroupRequest(42, polygon.DefaultTestset, "2").
+		Dependencies([]string{"0", "1"})))
+
+	table := new(textables.UniversalTable)
+	require.NoError(t, valeria.NewValeria(pc).InformaticsValuer(42, table, false))
+
+	require.Equal(t, `group 0 {
+	tests 1-2;
+	test_score 0;
+}
+
+group 1 {
+	tests 3-4;
+	score 40;
+}
+
+group 2 {
+	tests 5-7;
+	score 60;
+	requires 0,1;
+}
+`, srv.Problem(42).Files["valuer.cfg"].Content)
+	require.Contains(t, table.String(), "0 & 0 & тесты из условия &  \\\\ \\hline")
+	require.Contains(t, table.String(), "2 & 60 & --- & 0, 1 \\\\ \\hline")
+}
+
+func TestInterleavedGroups(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddScoredProblem(42, "aplusb",
+		[]string{"0", "1", "0", "1", "1", "2"},
+		[]float32{0, 20, 0, 20, 20, 40}, 0,
+	)
+	pc := polygon.NewPolygon(srv.Config())
+
+	table := new(textables.KalugaTable)
+	require.NoError(t, valeria.NewValeria(pc).InformaticsValuer(42, table, false))
+	require.Equal(t, `group 0 {
+	tests 1, 3;
+	score 0;
+}
+
+group 1 {
+	tests 2, 4-5;
+	score 60;
+}
+
+group 2 {
+	tests 6;
+	score 40;
+}
+`, srv.Problem(42).Files["valuer.cfg"].Content)
+}
+
+func TestSaveStatements(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddScoredProblem(42, "aplusb",
+		[]string{"0", "1", "1"},
+		[]float32{0, 40, 60}, 0,
+	)
+	pc := polygon.NewPolygon(srv.Config())
+	for _, lang := range []string{"russian", "english"} {
+		require.NoError(t, pc.SaveStatement(polygon.NewStatementRequest(42, lang).Legend("legend "+lang)))
+	}
+
+	val := valeria.NewValeria(pc)
+	val.UseStatements(map[string]textables.Table{"ukrainian": new(textables.UniversalTable)})
+	err := val.InformaticsValuer(42, new(textables.UniversalTable), false)
+	require.ErrorIs(t, err, valeria.ErrNoStatement)
+	require.NotContains(t, srv.Problem(42).Files, "valuer.cfg")
+
+	val.UseStatements(map[string]textables.Table{
+		"russian": &textables.UniversalTable{Lang: textables.Russian},
+		"english": &textables.UniversalTable{Lang: textables.English},
+	})
+	require.NoError(t, val.InformaticsValuer(42, new(textables.UniversalTable), false))
+
+	statements := srv.Problem(42).Statements
+	require.Equal(t, "legend english", statements["english"]["legend"])
+	require.Contains(t, statements["english"]["scoring"], `\textbf{Subtask}`)
+	require.Contains(t, statements["english"]["scoring"], "0 & 0 & sample tests")
+	require.Contains(t, statements["russian"]["scoring"], `\textbf{Подзадача}`)
+	require.Contains(t, srv.Problem(42).Files, "valuer.cfg")
+}
@@ -0,0 +1,102 @@
+package vydra
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Completed calls of the upload, one call id per line.
+type journal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	done map[string]bool
+}
+
+func DefaultJournalDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "algolymp", "vydra"), nil
+}
+
+// Journal is keyed by problem id and package revision.
+// Without resume the previous journal is dropped.
+func openJournal(dir string, pID, revision int, resume bool) (*journal, error) {
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, err
+	}
+	j := &journal{
+		path: filepath.Join(dir, fmt.Sprintf("%d-%d.journal", pID, revision)),
+		done: make(map[string]bool),
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if resume {
+		if err := j.read(); err != nil {
+			return nil, err
+		}
+	} else {
+		flags |= os.O_TRUNC
+	}
+	var err error
+	if j.file, err = os.OpenFile(j.path, flags, filePerm); err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{"path": j.path, "done": len(j.done)}).Info("open journal")
+
+	return j, nil
+}
+
+func (j *journal) read() error {
+	f, err := os.Open(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		j.done[sc.Text()] = true
+	}
+
+	return sc.Err()
+}
+
+func (j *journal) has(id string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.done[id]
+}
+
+func (j *journal) mark(id string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.done[id] = true
+	_, err := fmt.Fprintln(j.file, id)
+
+	return err
+}
+
+// Removes the journal if nothing is left to resume.
+func (j *journal) close(complete bool) error {
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	if complete {
+		return os.Remove(j.path)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package vydra
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	dirPerm  = 0755
+	filePerm = 0644
+)
+
+// In-memory package files by path, built by bober and enot.
+type Package map[string][]byte
+
+func (p Package) names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Write package into directory or .zip archive, the opposite of OpenPackage.
+func (p Package) Write(name string) error {
+	if !strings.HasSuffix(name, ".zip") {
+		return p.WriteDir(name)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteZip(f); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+func (p Package) WriteDir(dir string) error {
+	for _, name := range p.names() {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), dirPerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, p[name], filePerm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p Package) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, name := range p.names() {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(p[name]); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// Package as fs.FS, e.g. for upload without writing files.
+func (p Package) FS() (fs.FS, error) {
+	buf := &bytes.Buffer{}
+	if err := p.WriteZip(buf); err != nil {
+		return nil, err
+	}
+
+	return zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
@@ -0,0 +1,216 @@
+package vydra
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/sirupsen/logrus"
+)
+
+type call struct {
+	step   string
+	name   string
+	method string        // Polygon API method
+	fields logrus.Fields // key params
+	files  []string      // local files to upload
+	lines  []string      // generated content, e.g. script
+	issue  error         // found while building the stage
+	run    func() error
+
+	key  string                 // remote state key, empty if the state can't be read
+	want func() (string, error) // expected remote state after the call
+}
+
+func (c *call) api(method string, fields logrus.Fields) *call {
+	c.method = method
+	c.fields = fields
+
+	return c
+}
+
+func (c *call) file(paths ...string) *call {
+	c.files = append(c.files, paths...)
+
+	return c
+}
+
+func (c *call) state(key string, want func() (string, error)) *call {
+	c.key = key
+	c.want = want
+
+	return c
+}
+
+// Calls inside one stage are independent, stages run one after another.
+type stage struct {
+	name  string
+	calls []*call
+}
+
+func (st *stage) add(step, name string, run func() error) *call {
+	c := &call{step: step, name: name, run: run}
+	st.calls = append(st.calls, c)
+
+	return c
+}
+
+// Register an error found while building the stage.
+func (st *stage) fail(step, name string, err error) {
+	c := st.add(step, name, func() error { return err })
+	c.issue = err
+}
+
+type StepReport struct {
+	Step     string
+	OK       int
+	Skipped  int
+	Failed   int
+	Duration time.Duration
+}
+
+type Report struct {
+	Steps  []StepReport
+	Errors []error
+}
+
+func (r *Report) Failed() int {
+	return len(r.Errors)
+}
+
+func (r *Report) step(c *call) *StepReport {
+	for i := range r.Steps {
+		if r.Steps[i].Step == c.step {
+			return &r.Steps[i]
+		}
+	}
+	r.Steps = append(r.Steps, StepReport{Step: c.step})
+
+	return &r.Steps[len(r.Steps)-1]
+}
+
+func (r *Report) add(c *call, err error, d time.Duration) {
+	s := r.step(c)
+	s.Duration += d
+	switch {
+	case err == nil:
+		s.OK++
+	case errors.Is(err, polygon.ErrAlreadyExists):
+		s.Skipped++
+	default:
+		s.Failed++
+		r.Errors = append(r.Errors, fmt.Errorf("%s %s: %w", c.step, c.name, err))
+	}
+}
+
+// Nothing to do with the problem after these errors.
+func isFatal(err error) bool {
+	return errors.Is(err, polygon.ErrProblemNotFound) || errors.Is(err, polygon.ErrAccessDenied)
+}
+
+type result struct {
+	call     *call
+	err      error
+	duration time.Duration
+}
+
+func runStage(st *stage, workers int) <-chan result {
+	jobs := make(chan *call)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for range min(workers, len(st.calls)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				start := time.Now()
+				err := c.run()
+				results <- result{call: c, err: err, duration: time.Since(start)}
+			}
+		}()
+	}
+	go func() {
+		for _, c := range st.calls {
+			jobs <- c
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// Unique call id for the journal.
+func callID(st *stage, c *call) string {
+	return fmt.Sprintf("%s: %s %s", st.name, c.step, c.name)
+}
+
+// Drops the calls completed by the previous run.
+func (v *Vydra) resumeStage(st *stage, rep *Report) {
+	if v.journal == nil {
+		return
+	}
+	calls := st.calls[:0]
+	for _, c := range st.calls {
+		if v.journal.has(callID(st, c)) {
+			rep.step(c).Skipped++
+			logrus.WithFields(logrus.Fields{"step": c.step, "name": c.name}).Info("done before, skip")
+
+			continue
+		}
+		calls = append(calls, c)
+	}
+	st.calls = calls
+}
+
+func (v *Vydra) runStages(stages []stage) (*Report, error) {
+	rep := new(Report)
+	total := 0
+	for i := range stages {
+		v.resumeStage(&stages[i], rep)
+		total += len(stages[i].calls)
+	}
+	done := 0
+	for i := range stages {
+		st := &stages[i]
+		if len(st.calls) == 0 {
+			continue
+		}
+		logrus.WithFields(logrus.Fields{
+			"stage": st.name, "calls": len(st.calls), "workers": min(v.workers, len(st.calls)),
+		}).Info("start stage")
+		var fatal error
+		for res := range runStage(st, v.workers) {
+			done++
+			rep.add(res.call, res.err, res.duration)
+			entry := logrus.WithFields(logrus.Fields{
+				"progress": fmt.Sprintf("%d/%d", done, total),
+				"step":     res.call.step, "name": res.call.name,
+			})
+			switch {
+			case res.err == nil:
+				entry.Info("done")
+			case errors.Is(res.err, polygon.ErrAlreadyExists):
+				entry.WithError(res.err).Warn("already exists, skip")
+			default:
+				entry.WithError(res.err).Error("failed")
+			}
+			if v.journal != nil && (res.err == nil || errors.Is(res.err, polygon.ErrAlreadyExists)) {
+				if err := v.journal.mark(callID(st, res.call)); err != nil {
+					entry.WithError(err).Warn("failed to write journal")
+				}
+			}
+			if fatal == nil && isFatal(res.err) {
+				fatal = res.err
+			}
+		}
+		if fatal != nil {
+			return rep, fatal
+		}
+	}
+
+	return rep, nil
+}
@@ -0,0 +1,124 @@
+package vydra
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Print every API call without touching Polygon.
+// Returns the number of found issues.
+func (v *Vydra) Plan(w io.Writer) (int, error) {
+	if err := v.readXML("problem.xml"); err != nil {
+		return 0, err
+	}
+	pr := &planPrinter{w: w, fsys: v.fsys}
+	pr.printf("# package %s, revision %d, type %s\n", v.prob.ShortName, v.prob.Revision, v.packageType)
+	for _, testset := range v.prob.Judging.TestSets {
+		pr.printTestSet(&testset)
+	}
+	for _, st := range v.stages() {
+		pr.printStage(&st)
+	}
+	pr.printf("# %d issues found\n", pr.issues)
+
+	return pr.issues, pr.err
+}
+
+type planPrinter struct {
+	w      io.Writer
+	fsys   fs.FS
+	err    error
+	issues int
+}
+
+func (pr *planPrinter) printf(format string, a ...any) {
+	if pr.err != nil {
+		return
+	}
+	_, pr.err = fmt.Fprintf(pr.w, format, a...)
+}
+
+func (pr *planPrinter) issue(format string, a ...any) {
+	pr.issues++
+	pr.printf("! "+format+"\n", a...)
+}
+
+func (pr *planPrinter) printTestSet(testset *TestSet) {
+	var manual, generated, samples int
+	groups := make(map[string]int)
+	for _, t := range testset.Tests.Tests {
+		switch t.Method {
+		case "manual":
+			manual++
+		case "generated":
+			generated++
+		}
+		if t.Sample {
+			samples++
+		}
+		if t.Group != "" {
+			groups[t.Group]++
+		}
+	}
+	pr.printf("# testset %s: %d tests (%d manual, %d generated, %d samples), tl %d ms, ml %d MB\n",
+		testset.Name, len(testset.Tests.Tests), manual, generated, samples,
+		testset.TimeLimit, testset.MemoryLimit/megabyte)
+	if testset.TestCount != len(testset.Tests.Tests) {
+		pr.issue("testset %s: test-count is %d, but %d tests listed",
+			testset.Name, testset.TestCount, len(testset.Tests.Tests))
+	}
+	names := make([]string, 0, len(groups))
+	for g := range groups {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+	for _, g := range names {
+		pr.printf("#   group %s: %d tests\n", g, groups[g])
+	}
+}
+
+func (pr *planPrinter) printStage(st *stage) {
+	if len(st.calls) == 0 {
+		return
+	}
+	pr.printf("# stage %s: %d calls\n", st.name, len(st.calls))
+	for _, c := range st.calls {
+		if c.issue != nil {
+			pr.issue("%s %s: %v", c.step, c.name, c.issue)
+
+			continue
+		}
+		pr.printf("%s%s\n", c.method, formatFields(c.fields))
+		for _, path := range c.files {
+			info, err := fs.Stat(pr.fsys, path)
+			if err != nil {
+				pr.issue("%s %s: %v", c.step, c.name, err)
+
+				continue
+			}
+			pr.printf("\t%s (%d bytes)\n", path, info.Size())
+		}
+		for _, line := range c.lines {
+			pr.printf("\t%s\n", line)
+		}
+	}
+}
+
+func formatFields(fields logrus.Fields) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, fields[k])
+	}
+
+	return sb.String()
+}
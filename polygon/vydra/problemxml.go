@@ -1,8 +1,17 @@
 package vydra
 
+import "encoding/xml"
+
+// Group policies as written in problem.xml.
+const (
+	PointsCompleteGroup = "complete-group"
+	PointsEachTest      = "each-test"
+	FeedbackComplete    = "complete"
+)
+
 type File struct {
 	Path string `xml:"path,attr"`
-	Type string `xml:"type,attr"`
+	Type string `xml:"type,attr,omitempty"`
 }
 
 type Source struct {
@@ -27,19 +36,19 @@ type Statement struct {
 }
 
 type Test struct {
-	Description string  `xml:"description,attr"`
-	Method      string  `xml:"method,attr"`
-	Sample      bool    `xml:"sample,attr"`
-	Cmd         string  `xml:"cmd,attr"`
-	Verdict     string  `xml:"verdict,attr"`
-	Group       string  `xml:"group,attr"`
-	Points      float32 `xml:"points,attr"`
-	FromFile    string  `xml:"from-file,attr"`
+	Description string  `xml:"description,attr,omitempty"`
+	Method      string  `xml:"method,attr,omitempty"`
+	Sample      bool    `xml:"sample,attr,omitempty"`
+	Cmd         string  `xml:"cmd,attr,omitempty"`
+	Verdict     string  `xml:"verdict,attr,omitempty"`
+	Group       string  `xml:"group,attr,omitempty"`
+	Points      float32 `xml:"points,attr,omitempty"`
+	FromFile    string  `xml:"from-file,attr,omitempty"`
 }
 
 type Group struct {
-	FeedbackPolicy string  `xml:"feedback-policy,attr"`
-	PointsPolicy   string  `xml:"points-policy,attr"`
+	FeedbackPolicy string  `xml:"feedback-policy,attr,omitempty"`
+	PointsPolicy   string  `xml:"points-policy,attr,omitempty"`
 	Name           string  `xml:"name,attr"`
 	Points         float32 `xml:"points,attr"`
 	Dependencies   struct {
@@ -50,13 +59,13 @@ type Group struct {
 }
 
 type TestSet struct {
-	Name              string `xml:"name,attr"`
-	TimeLimit         int    `xml:"time-limit"`
-	MemoryLimit       int    `xml:"memory-limit"`
+	Name              string `xml:"name,attr,omitempty"`
+	TimeLimit         int    `xml:"time-limit,omitempty"`
+	MemoryLimit       int    `xml:"memory-limit,omitempty"`
 	TestCount         int    `xml:"test-count"`
-	InputPathPattern  string `xml:"input-path-pattern"`
-	OutputPathPattern string `xml:"output-path-pattern"`
-	AnswerPathPattern string `xml:"answer-path-pattern"`
+	InputPathPattern  string `xml:"input-path-pattern,omitempty"`
+	OutputPathPattern string `xml:"output-path-pattern,omitempty"`
+	AnswerPathPattern string `xml:"answer-path-pattern,omitempty"`
 	Tests             struct {
 		Tests []Test `xml:"test"`
 	} `xml:"tests"`
@@ -65,18 +74,37 @@ type TestSet struct {
 	} `xml:"groups"`
 }
 
+// Test input path pattern, Polygon default if problem.xml doesn't contain one.
+func (ts *TestSet) InputPattern() string {
+	return patternOr(ts.InputPathPattern, ts.Name+"/%02d")
+}
+
 type Validator struct {
 	Source  Source  `xml:"source"`
 	TestSet TestSet `xml:"testset"`
 }
 
+// Validator test input path pattern, Polygon default if problem.xml doesn't contain one.
+func (val *Validator) InputPattern() string {
+	return patternOr(val.TestSet.InputPathPattern, valTests+"/%02d")
+}
+
 type Checker struct {
-	Name    string  `xml:"name,attr"`
+	Name    string  `xml:"name,attr,omitempty"` // standard checker
 	Type    string  `xml:"type,attr"`
-	Source  Source  `xml:"source"`
+	Source  *Source `xml:"source"`
 	TestSet TestSet `xml:"testset"`
 }
 
+// Checker test input, output and answer path patterns, Polygon defaults if problem.xml doesn't contain them.
+func (chk *Checker) TestPatterns() (string, string, string) {
+	ts := &chk.TestSet
+
+	return patternOr(ts.InputPathPattern, chkTests+"/%02d"),
+		patternOr(ts.OutputPathPattern, chkTests+"/%02d.o"),
+		patternOr(ts.AnswerPathPattern, chkTests+"/%02d.a")
+}
+
 type Interactor struct {
 	Type   string `xml:"type,attr"`
 	Source Source `xml:"source"`
@@ -107,18 +135,19 @@ type Tag struct {
 }
 
 type Judging struct {
-	CPUName    string    `xml:"cpu-name,attr"`
-	CPUSpeed   int       `xml:"cpu-speed,attr"`
+	CPUName    string    `xml:"cpu-name,attr,omitempty"`
+	CPUSpeed   int       `xml:"cpu-speed,attr,omitempty"`
 	InputFile  string    `xml:"input-file,attr"`
 	OutputFile string    `xml:"output-file,attr"`
 	TestSets   []TestSet `xml:"testset"`
 }
 
 type ProblemXML struct {
-	Revision   int    `xml:"revision,attr"`
-	ShortName  string `xml:"short-name,attr"`
-	Assets     Assets `xml:"assets"`
-	Files      Files  `xml:"files"`
+	XMLName    xml.Name `xml:"problem"`
+	Revision   int      `xml:"revision,attr"`
+	ShortName  string   `xml:"short-name,attr"`
+	Assets     Assets   `xml:"assets"`
+	Files      Files    `xml:"files"`
 	Statements struct {
 		Statements []Statement `xml:"statement"`
 	} `xml:"statements"`
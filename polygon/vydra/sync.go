@@ -0,0 +1,313 @@
+package vydra
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrSyncCanceled = errors.New("sync canceled")
+
+// Resources of every new Polygon problem, usually missing in packages.
+//
+//nolint:gochecknoglobals // polygon defaults
+var builtinResources = map[string]bool{
+	"resource/olymp.sty":      true,
+	"resource/problem.tex":    true,
+	"resource/statements.ftl": true,
+	"resource/testlib.h":      true,
+}
+
+type ChangeOp string
+
+const (
+	ChangeAdd    ChangeOp = "+"
+	ChangeUpdate ChangeOp = "~"
+	ChangeDelete ChangeOp = "-"
+	ChangeRerun  ChangeOp = "?" // remote state can't be compared, so the call is repeated
+)
+
+type Change struct {
+	Op  ChangeOp
+	Key string
+	// Polygon API can't apply the change, it should be done by hand.
+	Manual bool
+}
+
+func (c Change) String() string {
+	if c.Manual {
+		return fmt.Sprintf("%s %s (remove manually)", c.Op, c.Key)
+	}
+	if c.Op == ChangeRerun {
+		return fmt.Sprintf("%s %s (upload again)", c.Op, c.Key)
+	}
+
+	return fmt.Sprintf("%s %s", c.Op, c.Key)
+}
+
+// Remote problem state, the keys are the same as local call keys.
+type remoteState map[string]string
+
+func (v *Vydra) readState(path string) func() (string, error) {
+	return func() (string, error) { return v.readFile(path) }
+}
+
+func constState(s string) func() (string, error) {
+	return func() (string, error) { return s, nil }
+}
+
+func infoState(input, output string, tl, ml int, interactive bool) string {
+	return fmt.Sprintf("input=%s output=%s tl=%d ml=%d interactive=%t", input, output, tl, ml, interactive)
+}
+
+func tagsState(tags []string) string {
+	tags = append([]string(nil), tags...)
+	sort.Strings(tags)
+
+	return strings.Join(tags, ",")
+}
+
+func solutionState(tag polygon.SolutionTag, content string) string {
+	return string(tag) + "\n" + content
+}
+
+func testState(manual bool, input, group string, points float32, sample bool) string {
+	s := fmt.Sprintf("group=%s points=%g sample=%t", group, points, sample)
+	if manual {
+		s += "\n" + input
+	}
+
+	return s
+}
+
+func groupState(pointsPolicy, feedbackPolicy string, deps []string) string {
+	return fmt.Sprintf("points=%s feedback=%s dependencies=%s", pointsPolicy, feedbackPolicy, tagsState(deps))
+}
+
+func statementSections(st *polygon.StatementAnswer) map[string]string {
+	return map[string]string{
+		"name": st.Name, "legend": st.Legend, "input": st.Input, "output": st.Output,
+		"scoring": st.Scoring, "interaction": st.Interaction, "notes": st.Notes, "tutorial": st.Tutorial,
+	}
+}
+
+func (v *Vydra) fetchRemote() (remoteState, error) {
+	rs := make(remoteState)
+	info, err := v.client.GetInfo(v.pID)
+	if err != nil {
+		return nil, err
+	}
+	rs["info"] = infoState(info.InputFile, info.OutputFile, info.TimeLimit, info.MemoryLimit, info.Interactive)
+	tags, err := v.client.GetTags(v.pID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) != 0 {
+		rs["tags"] = tagsState(tags)
+	}
+	if err := v.fetchFiles(rs); err != nil {
+		return nil, err
+	}
+	if err := v.fetchSolutions(rs); err != nil {
+		return nil, err
+	}
+	statements, err := v.client.GetStatements(v.pID)
+	if err != nil {
+		return nil, err
+	}
+	for lang, st := range statements {
+		for sec, text := range statementSections(&st) {
+			if text != "" {
+				rs[fmt.Sprintf("statement/%s/%s", lang, sec)] = text
+			}
+		}
+	}
+	for _, testset := range v.prob.Judging.TestSets {
+		if err := v.fetchTestSet(rs, testset.Name); err != nil {
+			return nil, err
+		}
+	}
+	logrus.WithField("keys", len(rs)).Info("fetch remote state")
+
+	return rs, nil
+}
+
+func (v *Vydra) fetchFiles(rs remoteState) error {
+	files, err := v.client.GetFiles(v.pID)
+	if err != nil {
+		return err
+	}
+	for _, list := range []struct {
+		typ   polygon.FileType
+		files []polygon.FileAnswer
+	}{{polygon.TypeResource, files.ResourceFiles}, {polygon.TypeSource, files.SourceFiles}} {
+		for _, f := range list.files {
+			content, err := v.client.ViewFile(v.pID, list.typ, f.Name)
+			if err != nil {
+				return err
+			}
+			rs[fmt.Sprintf("%s/%s", list.typ, f.Name)] = content
+		}
+	}
+
+	return nil
+}
+
+func (v *Vydra) fetchSolutions(rs remoteState) error {
+	solutions, err := v.client.GetSolutions(v.pID)
+	if err != nil {
+		return err
+	}
+	for _, sol := range solutions {
+		content, err := v.client.ViewSolution(v.pID, sol.Name)
+		if err != nil {
+			return err
+		}
+		rs["solution/"+sol.Name] = solutionState(sol.Tag, content)
+	}
+
+	return nil
+}
+
+func (v *Vydra) fetchTestSet(rs remoteState, testset string) error {
+	script, err := v.client.GetScript(v.pID, testset)
+	if err != nil {
+		return err
+	}
+	if script != "" {
+		rs["script/"+testset] = script
+	}
+	tests, err := v.client.GetTests(v.pID, testset)
+	if err != nil {
+		return err
+	}
+	for _, t := range tests {
+		if !t.Manual && t.Group == "" && t.Points == 0 && !t.UseInStatements {
+			continue // owned by the script
+		}
+		rs[fmt.Sprintf("test/%s/%d", testset, t.Index)] = testState(t.Manual, t.Input, t.Group, t.Points, t.UseInStatements)
+	}
+	groups, err := v.client.GetGroups(v.pID, testset)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		rs[fmt.Sprintf("group/%s/%s", testset, g.Name)] = groupState(g.PointsPolicy, g.FeedbackPolicy, g.Dependencies)
+	}
+
+	return nil
+}
+
+// Drops the calls which don't change the remote state.
+// Statement sections missing in the package are cleared only if prune is set.
+func diffStages(stages []stage, rs remoteState, prune bool) ([]stage, []Change) {
+	var changes []Change
+	seen := make(map[string]bool)
+	for i := range stages {
+		st := &stages[i]
+		calls := st.calls[:0]
+		for _, c := range st.calls {
+			if c.key == "" {
+				changes = append(changes, Change{Op: ChangeRerun, Key: c.step + "/" + c.name})
+				calls = append(calls, c)
+
+				continue
+			}
+			seen[c.key] = true
+			want, err := c.want()
+			if err != nil { // let it fail while running
+				changes = append(changes, Change{Op: ChangeRerun, Key: c.key})
+				calls = append(calls, c)
+
+				continue
+			}
+			have, ok := rs[c.key]
+			switch {
+			case !ok:
+				changes = append(changes, Change{Op: ChangeAdd, Key: c.key})
+			case have != want:
+				changes = append(changes, Change{Op: ChangeUpdate, Key: c.key})
+			default:
+				continue
+			}
+			calls = append(calls, c)
+		}
+		st.calls = calls
+	}
+
+	keys := make([]string, 0, len(rs))
+	for key := range rs {
+		if !seen[key] && !builtinResources[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		changes = append(changes, Change{Op: ChangeDelete, Key: key, Manual: !canDelete(key, prune)})
+	}
+
+	return stages, changes
+}
+
+// Only statement sections and tags can be cleared through the API.
+// Sections may be written by other tools, e.g. valeria scoring, so they are kept by default.
+func canDelete(key string, prune bool) bool {
+	return key == "tags" || prune && strings.HasPrefix(key, "statement/")
+}
+
+func (v *Vydra) stageCleanup(changes []Change) stage {
+	st := stage{name: "cleanup"}
+	for _, ch := range changes {
+		if ch.Op != ChangeDelete || ch.Manual {
+			continue
+		}
+		if ch.Key == "tags" {
+			st.add("tags", "tags", func() error { return v.uploadTags(nil) }).
+				api("problem.saveTags", logrus.Fields{"count": 0})
+
+			continue
+		}
+		parts := strings.SplitN(ch.Key, "/", 3) //nolint:mnd // statement/lang/section
+		lang, section := parts[1], parts[2]
+		st.add("statement", ch.Key, func() error { return v.clearStatement(lang, section) }).
+			api("problem.saveStatement", logrus.Fields{"lang": lang, "section": section})
+	}
+
+	return st
+}
+
+// Upload only the difference between the package and the remote problem.
+// Confirm is called with the changes before touching the problem.
+func (v *Vydra) Sync(confirm func([]Change) bool) (*Report, error) {
+	if err := v.readXML("problem.xml"); err != nil {
+		return nil, err
+	}
+	rs, err := v.fetchRemote()
+	if err != nil {
+		return nil, err
+	}
+	stages, changes := diffStages(v.stages(), rs, v.prune)
+	manual := 0
+	for _, ch := range changes {
+		if ch.Manual {
+			manual++
+		}
+	}
+	if manual == len(changes) { // nothing to do through the API, repeated calls included
+		for _, ch := range changes {
+			logrus.WithField("key", ch.Key).Warn("remove manually")
+		}
+		logrus.Info("problem is up to date")
+
+		return new(Report), nil
+	}
+	if !confirm(changes) {
+		return nil, ErrSyncCanceled
+	}
+
+	return v.runStages(append(stages, v.stageCleanup(changes)))
+}
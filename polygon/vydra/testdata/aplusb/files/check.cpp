@@ -0,0 +1,7 @@
+#include "testlib.h"
+
+int main(int argc, char* argv[]) {
+    registerTestlibCmd(argc, argv);
+    ensure(ouf.readLong() == ans.readLong());
+    quitf(_ok, "ok");
+}
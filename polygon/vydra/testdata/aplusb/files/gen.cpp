@@ -0,0 +1,8 @@
+#include "testlib.h"
+#include <iostream>
+
+int main(int argc, char* argv[]) {
+    registerGen(argc, argv, 1);
+    long long n = atoll(argv[1]);
+    std::cout << n << " " << n << std::endl;
+}
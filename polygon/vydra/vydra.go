@@ -1,8 +1,12 @@
 package vydra
 
 import (
+	"archive/zip"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"strings"
 
@@ -19,11 +23,13 @@ const (
 	defaultInput  = "stdin"
 	defaultOutput = "stdout"
 
+	valTests = "files/tests/validator-tests"
 	chkTests = "files/tests/checker-tests"
 )
 
 var (
-	ErrBadSolutionTag = errors.New("bad solution tag")
+	ErrUnusedFiles   = errors.New("files are not listed in problem.xml")
+	ErrTestSetLimits = errors.New("testset limits differ")
 )
 
 type PackageType string
@@ -36,8 +42,15 @@ const (
 
 type Vydra struct {
 	client      *polygon.Polygon
+	fsys        fs.FS
 	pID         int
 	isLegacy    bool
+	mapping     *polygon.Mapping
+	workers     int
+	journalDir  string
+	resume      bool
+	journal     *journal
+	prune       bool
 	packageType PackageType
 	prob        ProblemXML
 	streamIn    *natstream.NatStream
@@ -45,8 +58,8 @@ type Vydra struct {
 	streamAns   *natstream.NatStream
 }
 
-func getpackageType() PackageType { // problemxml doesn't contain type package
-	entries, _ := os.ReadDir("./")
+func getpackageType(fsys fs.FS) PackageType { // problemxml doesn't contain type package
+	entries, _ := fs.ReadDir(fsys, ".")
 	for _, e := range entries {
 		if e.Name() == "wipe.sh" {
 			return StandartPackage
@@ -61,43 +74,153 @@ func getpackageType() PackageType { // problemxml doesn't contain type package
 	return LinuxPackage
 }
 
-func NewVydra(client *polygon.Polygon, pID int, isLegacy bool) *Vydra {
+// Package content is read from fsys, e.g. os.DirFS or zip.Reader.
+func NewVydra(client *polygon.Polygon, fsys fs.FS, pID int, isLegacy bool, workers int) *Vydra {
 	return &Vydra{
 		client:      client,
+		fsys:        fsys,
 		pID:         pID,
 		isLegacy:    isLegacy,
-		packageType: getpackageType(),
+		mapping:     polygon.DefaultMapping(),
+		workers:     max(workers, 1),
+		packageType: getpackageType(fsys),
 		streamIn:    new(natstream.NatStream),
 		streamOut:   new(natstream.NatStream),
 		streamAns:   new(natstream.NatStream),
 	}
 }
 
+// Polygon default path pattern is used if problem.xml doesn't contain one.
+func patternOr(pattern, def string) string {
+	if pattern != "" {
+		return pattern
+	}
+
+	return def
+}
+
+// Files matching the stream patterns, but out of the test count, are reported as the step issue.
+func checkUnused(st *stage, step, name string, streams ...*natstream.NatStream) {
+	for _, ns := range streams {
+		unused, err := ns.Unused()
+		if err == nil && len(unused) != 0 {
+			err = fmt.Errorf("%w: %s", ErrUnusedFiles, strings.Join(unused, ", "))
+		}
+		if err != nil {
+			st.fail(step, name, err)
+		}
+	}
+}
+
 // Convert string from .xml to API.
 func convertString(s string) string {
 	return strings.ToUpper(strings.ReplaceAll(s, "-", "_")) // oh my God
 }
 
-func (v *Vydra) Upload(errs chan error) error {
-	defer close(errs)
+// Sync clears remote statement sections missing in the package.
+func (v *Vydra) UsePrune(prune bool) {
+	v.prune = prune
+}
+
+// Override the embedded solution tags and source types mapping.
+func (v *Vydra) UseMapping(m *polygon.Mapping) {
+	v.mapping = m
+}
+
+func (v *Vydra) sourceType(typ string) string {
+	return v.mapping.SourceType(typ, v.isLegacy)
+}
+
+// Write completed calls into the journal in dir.
+// With resume, calls completed by the previous run of the same package revision are skipped.
+func (v *Vydra) UseJournal(dir string, resume bool) {
+	v.journalDir = dir
+	v.resume = resume
+}
+
+func (v *Vydra) Upload() (*Report, error) {
 	if err := v.readXML("problem.xml"); err != nil {
-		return err
+		return nil, err
 	}
-	v.batchInitial(errs)
-	v.batchValChk(errs)
-	v.batchJudging(errs)
+	if v.journalDir == "" {
+		return v.runStages(v.stages())
+	}
+	var err error
+	if v.journal, err = openJournal(v.journalDir, v.pID, v.prob.Revision, v.resume); err != nil {
+		return nil, err
+	}
+	rep, err := v.runStages(v.stages())
+	if cerr := v.journal.close(err == nil && rep.Failed() == 0); cerr != nil {
+		logrus.WithError(cerr).Warn("failed to close journal")
+	}
+	v.journal = nil
 
-	return nil
+	return rep, err
+}
+
+func (v *Vydra) stages() []stage {
+	stages := v.stagesInitial()
+	stages = append(stages, v.stagesValChk()...)
+
+	return append(stages, v.stagesJudging()...)
+}
+
+func (v *Vydra) readFile(path string) (string, error) {
+	data, err := fs.ReadFile(v.fsys, path)
+
+	return string(data), err
+}
+
+// Directory or zip archive, as downloaded by gibon.
+func OpenPackage(name string) (fs.FS, io.Closer, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return os.DirFS(name), io.NopCloser(nil), nil
+	}
+	logrus.WithField("path", name).Info("open package zip")
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return zr, zr, nil
+}
+
+func ReadProblemXML(fsys fs.FS, path string) (*ProblemXML, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	var prob ProblemXML
+	if err := xml.Unmarshal(data, &prob); err != nil {
+		return nil, err
+	}
+
+	return &prob, nil
+}
+
+// Indented problem.xml with the header, as Polygon writes it.
+func (p *ProblemXML) Marshal() ([]byte, error) {
+	data, err := xml.MarshalIndent(p, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
 }
 
 func (v *Vydra) readXML(path string) error {
-	data, err := os.ReadFile(path)
+	prob, err := ReadProblemXML(v.fsys, path)
 	if err != nil {
 		return err
 	}
-	if err := xml.Unmarshal(data, &v.prob); err != nil {
+	if err := checkLimits(&prob.Judging); err != nil {
 		return err
 	}
+	v.prob = *prob
 	logrus.WithFields(logrus.Fields{
 		"revision": v.prob.Revision, "short-name": v.prob.ShortName,
 	}).Info("load problem.xml")
@@ -0,0 +1,266 @@
+package vydra_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+	fsys := os.DirFS("testdata/aplusb")
+
+	rep, err := vydra.NewVydra(pc, fsys, 42, false, 4).Upload()
+	require.NoError(t, err)
+	require.Empty(t, rep.Errors)
+
+	require.Equal(t, 2000, prob.TimeLimit)
+	require.Equal(t, 256, prob.MemoryLimit)
+	require.Equal(t, []string{"math", "implementation"}, prob.Tags)
+	require.Len(t, prob.Files, 5)
+	require.Equal(t, polygon.TagMain, prob.Solutions["main.cpp"].Tag)
+	require.Equal(t, polygon.TagWrongAnswer, prob.Solutions["overflow.cpp"].Tag)
+	require.Equal(t, "Print $a + b$.\n", prob.Statements["english"]["output"])
+	require.Equal(t, "check.cpp", prob.Checker)
+	require.Equal(t, "val.cpp", prob.Validator)
+	require.Len(t, prob.ValidatorTests, 2)
+	require.Equal(t, "INVALID", prob.ValidatorTests[2].Verdict)
+	require.Equal(t, "4\n", prob.CheckerTests[1].Output)
+
+	tests := prob.Tests[polygon.DefaultTestset]
+	require.Len(t, tests, 3)
+	require.True(t, tests[1].UseInStatements)
+	require.Equal(t, "1000000000000 1000000000000\n", tests[2].Input)
+	require.False(t, tests[3].Manual)
+	require.Equal(t, "1", tests[3].Group)
+	require.Equal(t, "gen 3 > 3", prob.Scripts[polygon.DefaultTestset])
+
+	groups := prob.Groups[polygon.DefaultTestset]
+	require.Equal(t, polygon.PolicyEachTest, groups["0"].PointsPolicy)
+	require.Equal(t, []string{"0"}, groups["1"].Dependencies)
+
+	require.Equal(t, "1 2\n", prob.Tests["pretests"][1].Input)
+	require.Equal(t, map[string]string{"pic.png": "fake png\n"}, prob.Resources)
+}
+
+func TestUploadReport(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+	fsys := os.DirFS("testdata/aplusb")
+
+	rep, err := vydra.NewVydra(pc, fsys, 42, false, 1).Upload()
+	require.NoError(t, err)
+	steps := make(map[string]int)
+	for _, s := range rep.Steps {
+		steps[s.Step] = s.OK
+	}
+	require.Equal(t, map[string]int{
+		"problem": 1, "tags": 1, "solution": 2, "resource": 2, "executable": 3, "statement": 8,
+		"statement resource": 1, "validator": 1, "checker": 1, "validator test": 2, "checker test": 1,
+		"script": 1, "enable groups": 1, "enable points": 1, "test": 4, "group": 2,
+	}, steps)
+
+	_, err = vydra.NewVydra(pc, fsys, 43, false, 4).Upload()
+	require.ErrorIs(t, err, polygon.ErrProblemNotFound)
+}
+
+func TestUploadZip(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	require.NoError(t, zw.AddFS(os.DirFS("testdata/aplusb")))
+	require.NoError(t, zw.Close())
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	rep, err := vydra.NewVydra(pc, zr, 42, false, 4).Upload()
+	require.NoError(t, err)
+	require.Empty(t, rep.Errors)
+	require.Len(t, prob.Tests[polygon.DefaultTestset], 3)
+	require.Equal(t, "gen 3 > 3", prob.Scripts[polygon.DefaultTestset])
+}
+
+func TestScript(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "multigen")
+	pc := polygon.NewPolygon(srv.Config())
+	fsys := os.DirFS("testdata/multigen")
+
+	rep, err := vydra.NewVydra(pc, fsys, 42, false, 4).Upload()
+	require.NoError(t, err)
+	require.Empty(t, rep.Errors)
+	require.Equal(t, "gen 1 > 2\nmultigen 7 > {3-5}\ngen 2 > 7", prob.Scripts[polygon.DefaultTestset])
+	tests := prob.Tests[polygon.DefaultTestset]
+	require.Len(t, tests, 7)
+	require.Equal(t, "6\n", tests[6].Input)
+
+	withScript := fstest.MapFS{"files/script": {Data: []byte("<#list 2..3 as i>gen ${i} > $</#list>\n")}}
+	require.NoError(t, fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, p)
+		withScript[p] = &fstest.MapFile{Data: data}
+
+		return err
+	}))
+	var sb strings.Builder
+	_, err = vydra.NewVydra(nil, withScript, 42, false, 4).Plan(&sb)
+	require.NoError(t, err)
+	require.Contains(t, sb.String(), "problem.saveScript lines=1 testset=tests\n\tfiles/script (38 bytes)\n")
+}
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	fsys := os.DirFS("testdata/aplusb")
+
+	var sb strings.Builder
+	issues, err := vydra.NewVydra(nil, fsys, 42, false, 4).Plan(&sb)
+	require.NoError(t, err)
+	require.Zero(t, issues)
+	plan := sb.String()
+	require.Contains(t, plan, "# testset tests: 3 tests (2 manual, 1 generated, 1 samples), tl 2000 ms, ml 256 MB\n")
+	require.Contains(t, plan, "problem.saveScript lines=1 testset=tests\n\tgen 3 > 3\n")
+	require.Contains(t, plan, "problem.saveTest group=1 method=manual points=50 sample=false testIndex=2 testset=tests\n"+
+		"\ttests/02 (28 bytes)\n")
+	require.Empty(t, srv.Calls())
+}
+
+func TestPlanIssues(t *testing.T) {
+	t.Parallel()
+	fsys := os.DirFS("testdata/broken")
+
+	var sb strings.Builder
+	issues, err := vydra.NewVydra(nil, fsys, 42, false, 4).Plan(&sb)
+	require.NoError(t, err)
+	require.Equal(t, 4, issues)
+	plan := sb.String()
+	require.Contains(t, plan, "! solution solutions/main.cpp: stat solutions/main.cpp: no such file or directory\n")
+	require.Contains(t, plan, "! solution solutions/magic.cpp: bad solution tag: magic\n")
+	require.Contains(t, plan, "! test tests/2: missing file: tests/02\n")
+	require.Contains(t, plan, "! test tests: files are not listed in problem.xml: tests/03\n")
+
+	xml, err := fs.ReadFile(os.DirFS("testdata/aplusb"), "problem.xml")
+	require.NoError(t, err)
+	xml = bytes.Replace(xml, []byte("<time-limit>2000"), []byte("<time-limit>1000"), 1) // tests only
+	_, err = vydra.NewVydra(nil, fstest.MapFS{"problem.xml": {Data: xml}}, 42, false, 4).Plan(&sb)
+	require.ErrorIs(t, err, vydra.ErrTestSetLimits)
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+	fsys := os.DirFS("testdata/aplusb")
+
+	vyd := vydra.NewVydra(pc, fsys, 42, false, 4)
+	_, err := vyd.Upload()
+	require.NoError(t, err)
+	prob.Files["statements.ftl"] = &fakepolygon.File{Type: polygon.TypeResource, Content: "<#-- default -->"}
+
+	var reruns []string
+	rep, err := vyd.Sync(func(cs []vydra.Change) bool {
+		for _, c := range cs {
+			require.Equal(t, vydra.ChangeRerun, c.Op, c.String())
+			reruns = append(reruns, c.Key)
+		}
+
+		return true
+	})
+	require.NoError(t, err)
+	require.Empty(t, rep.Errors)
+	require.Equal(t, []string{
+		"statement resource/statement-sections/russian/pic.png", "validator/files/val.cpp",
+		"checker/check.cpp", "validator test/1", "validator test/2", "checker test/1",
+		"enable groups/tests", "enable points/tests",
+	}, reruns)
+
+	prob.Solutions["main.cpp"].Content = "int main() {}"
+	prob.Solutions["old.cpp"] = &fakepolygon.Solution{Tag: polygon.TagCorrect}
+	prob.Statements["english"]["notes"] = "Outdated notes."
+	prob.Statements["english"]["scoring"] = "Scoring table."
+	prob.Tests[polygon.DefaultTestset][2].Points = 10
+
+	var changes []string
+	_, err = vyd.Sync(func(cs []vydra.Change) bool {
+		for _, c := range cs {
+			if c.Op != vydra.ChangeRerun {
+				changes = append(changes, c.String())
+			}
+		}
+
+		return false
+	})
+	require.ErrorIs(t, err, vydra.ErrSyncCanceled)
+	require.Equal(t, []string{
+		"~ solution/main.cpp",
+		"~ test/tests/2",
+		"- solution/old.cpp (remove manually)",
+		"- statement/english/notes (remove manually)",
+		"- statement/english/scoring (remove manually)",
+	}, changes)
+
+	calls := len(srv.Calls())
+	rep, err = vyd.Sync(func([]vydra.Change) bool { return true })
+	require.NoError(t, err)
+	require.Empty(t, rep.Errors)
+	require.NotContains(t, srv.Calls()[calls:], "problem.saveFile")
+	require.Contains(t, srv.Calls()[calls:], "problem.setChecker")
+	require.Equal(t, "Scoring table.", prob.Statements["english"]["scoring"])
+	require.Contains(t, prob.Solutions["main.cpp"].Content, "cin")
+	require.InDelta(t, 50, prob.Tests[polygon.DefaultTestset][2].Points, 0)
+
+	vyd.UsePrune(true)
+	rep, err = vyd.Sync(func([]vydra.Change) bool { return true })
+	require.NoError(t, err)
+	require.Empty(t, rep.Errors)
+	require.Equal(t, "", prob.Statements["english"]["notes"])
+	require.Equal(t, "", prob.Statements["english"]["scoring"])
+}
+
+func TestResume(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+	dir := t.TempDir()
+	fsys := os.DirFS("testdata/aplusb")
+
+	srv.Fail("problem.saveTest", 1, `{"status":"FAILED","comment":"testInput: broken"}`)
+	vyd := vydra.NewVydra(pc, fsys, 42, false, 1)
+	vyd.UseJournal(dir, false)
+	rep, err := vyd.Upload()
+	require.NoError(t, err)
+	require.Equal(t, 2, rep.Failed()) // the sample and its group
+	require.FileExists(t, dir+"/42-7.journal")
+
+	calls := len(srv.Calls())
+	vyd.UseJournal(dir, true)
+	rep, err = vyd.Upload()
+	require.NoError(t, err)
+	require.Zero(t, rep.Failed())
+	require.Equal(t, []string{"problem.saveTest", "problem.saveTestGroup"}, srv.Calls()[calls:])
+	require.NoFileExists(t, dir+"/42-7.journal")
+}
@@ -3,39 +3,81 @@ package vydra
 import (
 	"fmt"
 	"io/fs"
-	"os"
-	"path/filepath"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/Gornak40/algolymp/polygon"
 	"github.com/sirupsen/logrus"
 )
 
-func (v *Vydra) initProblem(judge *Judging) error {
-	input := defaultInput
+//nolint:gochecknoglobals // statement sections
+var statementFiles = []string{
+	"input.tex", "output.tex", "legend.tex", "name.tex", "notes.tex",
+	"tutorial.tex", "interaction.tex", "scoring.tex",
+}
+
+type problemInfo struct {
+	input       string
+	output      string
+	tl          int
+	ml          int
+	interactive bool
+}
+
+func (v *Vydra) problemInfo(judge *Judging) *problemInfo {
+	info := &problemInfo{
+		input:       defaultInput,
+		output:      defaultOutput,
+		tl:          defaultTL,
+		ml:          defaultML,
+		interactive: v.prob.Assets.Interactor != nil,
+	}
 	if judge.InputFile != "" {
-		input = judge.InputFile
+		info.input = judge.InputFile
 	}
-	output := defaultOutput
 	if judge.OutputFile != "" {
-		output = judge.OutputFile
+		info.output = judge.OutputFile
+	}
+	// Polygon API sets limits of the default testset only.
+	idx := slices.IndexFunc(judge.TestSets, func(ts TestSet) bool { return ts.Name == polygon.DefaultTestset })
+	if idx == -1 && len(judge.TestSets) != 0 {
+		idx = 0
 	}
-	tl := defaultTL
-	ml := defaultML
-	if len(judge.TestSets) != 0 {
-		tl = judge.TestSets[0].TimeLimit
-		ml = judge.TestSets[0].MemoryLimit / megabyte
+	if idx != -1 {
+		info.tl = judge.TestSets[idx].TimeLimit
+		info.ml = judge.TestSets[idx].MemoryLimit / megabyte
 	}
-	isIntaractive := (v.prob.Assets.Interactor != nil)
 
+	return info
+}
+
+// Polygon API can't set limits per testset, so the package is rejected rather than uploaded with wrong ones.
+func checkLimits(judge *Judging) error {
+	if len(judge.TestSets) == 0 {
+		return nil
+	}
+	first := judge.TestSets[0]
+	for _, ts := range judge.TestSets[1:] {
+		if ts.TimeLimit != first.TimeLimit || ts.MemoryLimit != first.MemoryLimit {
+			return fmt.Errorf("%w: testset %s has tl %d ms, ml %d MB, testset %s has tl %d ms, ml %d MB",
+				ErrTestSetLimits, ts.Name, ts.TimeLimit, ts.MemoryLimit/megabyte,
+				first.Name, first.TimeLimit, first.MemoryLimit/megabyte)
+		}
+	}
+
+	return nil
+}
+
+func (v *Vydra) initProblem(info *problemInfo) error {
 	logrus.WithFields(logrus.Fields{
-		"input": input, "output": output,
-		"tl": tl, "ml": ml, "interactive": isIntaractive,
+		"input": info.input, "output": info.output,
+		"tl": info.tl, "ml": info.ml, "interactive": info.interactive,
 	}).Info("init problem")
 
 	pr := polygon.NewProblemRequest(v.pID).
-		InputFile(input).OutputFile(output).
-		TimeLimit(tl).MemoryLimit(ml).Interactive(isIntaractive)
+		InputFile(info.input).OutputFile(info.output).
+		TimeLimit(info.tl).MemoryLimit(info.ml).Interactive(info.interactive)
 
 	return v.client.UpdateInfo(pr)
 }
@@ -44,13 +86,13 @@ func (v *Vydra) uploadExecutable(exe *Executable) error {
 	logrus.WithFields(logrus.Fields{
 		"path": exe.Source.Path, "type": exe.Source.Type,
 	}).Info("upload executable")
-	data, err := os.ReadFile(exe.Source.Path)
+	data, err := v.readFile(exe.Source.Path)
 	if err != nil {
 		return err
 	}
 
-	fr := polygon.NewFileRequest(v.pID, polygon.TypeSource, filepath.Base(exe.Source.Path), string(data)).
-		SourceType(exe.Source.Type, v.isLegacy)
+	fr := polygon.NewFileRequest(v.pID, polygon.TypeSource, path.Base(exe.Source.Path), data).
+		SourceType(v.sourceType(exe.Source.Type))
 
 	return v.client.SaveFile(fr)
 }
@@ -59,37 +101,18 @@ func (v *Vydra) uploadSolution(sol *Solution) error {
 	logrus.WithFields(logrus.Fields{
 		"path": sol.Source.Path, "type": sol.Source.Type, "tag": sol.Tag,
 	}).Info("upload solution")
-	data, err := os.ReadFile(sol.Source.Path)
+	data, err := v.readFile(sol.Source.Path)
 	if err != nil {
 		return err
 	}
 
-	var tag polygon.SolutionTag
-	switch sol.Tag { // TODO: add other tags
-	case "main":
-		tag = polygon.TagMain
-	case "accepted":
-		tag = polygon.TagCorrect
-	case "rejected":
-		tag = polygon.TagIncorrect
-	case "time-limit-exceeded":
-		tag = polygon.TagTimeLimit
-	case "wrong-answer":
-		tag = polygon.TagWrongAnswer
-	case "time-limit-exceeded-or-accepted":
-		tag = polygon.TagTLorOK
-	case "time-limit-exceeded-or-memory-limit-exceeded":
-		tag = polygon.TagTLorML
-	case "presentation-error":
-		tag = polygon.TagPresentationError
-	case "memory-limit-exceeded":
-		tag = polygon.TagMemoryLimit
-	default:
-		return fmt.Errorf("%w: %s", ErrBadSolutionTag, sol.Tag)
-	}
-
-	sr := polygon.NewSolutionRequest(v.pID, filepath.Base(sol.Source.Path), string(data), tag).
-		SourceType(sol.Source.Type, v.isLegacy)
+	tag, err := v.mapping.SolutionTag(sol.Tag)
+	if err != nil {
+		return err
+	}
+
+	sr := polygon.NewSolutionRequest(v.pID, path.Base(sol.Source.Path), data, tag).
+		SourceType(v.sourceType(sol.Source.Type))
 
 	return v.client.SaveSolution(sr)
 }
@@ -98,62 +121,115 @@ func (v *Vydra) uploadResource(res *File) error {
 	logrus.WithFields(logrus.Fields{
 		"path": res.Path, "type": res.Type,
 	}).Info("upload resource")
-	data, err := os.ReadFile(res.Path)
+	data, err := v.readFile(res.Path)
 	if err != nil {
 		return err
 	}
 
-	fr := polygon.NewFileRequest(v.pID, polygon.TypeResource, filepath.Base(res.Path), string(data))
+	fr := polygon.NewFileRequest(v.pID, polygon.TypeResource, path.Base(res.Path), data)
 
 	return v.client.SaveFile(fr)
 }
 
-func (v *Vydra) uploadStatement(stat *Statement) error {
-	if stat.Type != "application/x-tex" {
-		return nil
+func setSection(sr polygon.StatementRequest, section, text string) {
+	switch section {
+	case "input":
+		sr.Input(text)
+	case "output":
+		sr.Output(text)
+	case "legend":
+		sr.Legend(text)
+	case "name":
+		sr.Name(text)
+	case "notes":
+		sr.Notes(text)
+	case "tutorial":
+		sr.Tutorial(text)
+	case "interaction":
+		sr.Interaction(text)
+	case "scoring":
+		sr.Scoring(text)
+	}
+}
+
+func (v *Vydra) uploadStatement(lang, charset, file string) error {
+	data, err := v.readFile(file)
+	if err != nil {
+		return err
 	}
+	sr := polygon.NewStatementRequest(v.pID, lang).
+		Encoding(charset)
+	setSection(sr, strings.TrimSuffix(path.Base(file), ".tex"), data)
 	logrus.WithFields(logrus.Fields{
-		"language": stat.Language, "type": stat.Type, "charset": stat.Charset,
-	}).Info("upload statement")
+		"path": file, "language": lang, "charset": charset,
+	}).Info("upload statement section")
+
+	return v.client.SaveStatement(sr)
+}
+
+func (v *Vydra) clearStatement(lang, section string) error {
+	logrus.WithFields(logrus.Fields{
+		"language": lang, "section": section,
+	}).Info("clear statement section")
+	sr := polygon.NewStatementRequest(v.pID, lang)
+	setSection(sr, section, "")
+
+	return v.client.SaveStatement(sr)
+}
+
+func (v *Vydra) uploadStatementResource(file string) error {
+	logrus.WithField("path", file).Info("upload statement resource")
+	data, err := v.readFile(file)
+	if err != nil {
+		return err
+	}
+
+	return v.client.SaveStatementResource(v.pID, path.Base(file), data)
+}
+
+// Statement resources are shared between languages, so seen names are skipped.
+func (v *Vydra) addStatementResource(st *stage, file string, seen map[string]bool) {
+	name := path.Base(file)
+	if strings.HasPrefix(name, "example.") || seen[name] { // examples are generated by Polygon
+		return
+	}
+	seen[name] = true
+	st.add("statement resource", file, func() error { return v.uploadStatementResource(file) }).
+		api("problem.saveStatementResource", logrus.Fields{"name": name}).
+		file(file)
+}
+
+func (v *Vydra) addStatement(st *stage, stat *Statement, seen map[string]bool) {
+	if stat.Type != "application/x-tex" {
+		return
+	}
 	dir := "statement-sections/" + stat.Language
 
-	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(v.fsys, dir, func(file string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if d.IsDir() {
 			return nil
 		}
-		data, err := os.ReadFile(path) //nolint:gosec
-		if err != nil {
-			return err
-		}
-		sr := polygon.NewStatementRequest(v.pID, stat.Language).
-			Encoding(stat.Charset)
-		switch filepath.Base(path) {
-		case "input.tex":
-			sr.Input(string(data))
-		case "output.tex":
-			sr.Output(string(data))
-		case "legend.tex":
-			sr.Legend(string(data))
-		case "name.tex":
-			sr.Name(string(data))
-		case "notes.tex":
-			sr.Notes(string(data))
-		case "tutorial.tex":
-			sr.Tutorial(string(data))
-		case "interaction.tex":
-			sr.Interaction(string(data))
-		case "scoring.tex":
-			sr.Scoring(string(data))
-		default:
+		name := path.Base(file)
+		if !slices.Contains(statementFiles, name) {
+			v.addStatementResource(st, file, seen)
+
 			return nil
 		}
-		logrus.WithField("path", path).Info("upload statement section")
+		section := strings.TrimSuffix(name, ".tex")
+		st.add("statement", file, func() error {
+			return v.uploadStatement(stat.Language, stat.Charset, file)
+		}).api("problem.saveStatement", logrus.Fields{
+			"lang": stat.Language, "section": section,
+		}).file(file).state(fmt.Sprintf("statement/%s/%s", stat.Language, section), v.readState(file))
 
-		return v.client.SaveStatement(sr)
+		return nil
 	})
+	if err != nil {
+		st.fail("statement", dir, err)
+	}
 }
 
 func (v *Vydra) uploadTags(tags []Tag) error {
@@ -167,21 +243,55 @@ func (v *Vydra) uploadTags(tags []Tag) error {
 	return v.client.SaveTags(v.pID, line)
 }
 
-func (v *Vydra) batchInitial(errs chan error) {
-	errs <- v.initProblem(&v.prob.Judging)
+func (v *Vydra) stagesInitial() []stage {
+	st := stage{name: "initial"}
+	info := v.problemInfo(&v.prob.Judging)
+	st.add("problem", "info", func() error { return v.initProblem(info) }).
+		api("problem.updateInfo", logrus.Fields{
+			"inputFile": info.input, "outputFile": info.output,
+			"timeLimit": info.tl, "memoryLimit": info.ml, "interactive": info.interactive,
+		}).state("info", constState(infoState(info.input, info.output, info.tl, info.ml, info.interactive)))
 	if tags := v.prob.Tags.Tags; len(tags) != 0 {
-		errs <- v.uploadTags(tags)
+		values := make([]string, 0, len(tags))
+		for _, t := range tags {
+			values = append(values, t.Value)
+		}
+		st.add("tags", "tags", func() error { return v.uploadTags(tags) }).
+			api("problem.saveTags", logrus.Fields{"count": len(tags)}).
+			state("tags", constState(tagsState(values)))
 	}
 	for _, sol := range v.prob.Assets.Solutions.Solutions {
-		errs <- v.uploadSolution(&sol)
+		tag, err := v.mapping.SolutionTag(sol.Tag)
+		if err != nil {
+			st.fail("solution", sol.Source.Path, err)
+
+			continue
+		}
+		read := v.readState(sol.Source.Path)
+		st.add("solution", sol.Source.Path, func() error { return v.uploadSolution(&sol) }).
+			api("problem.saveSolution", logrus.Fields{"tag": sol.Tag, "type": v.sourceType(sol.Source.Type)}).
+			file(sol.Source.Path).
+			state("solution/"+path.Base(sol.Source.Path), func() (string, error) {
+				content, err := read()
+
+				return solutionState(tag, content), err
+			})
 	}
 	for _, res := range v.prob.Files.Resources.Files {
-		errs <- v.uploadResource(&res)
+		st.add("resource", res.Path, func() error { return v.uploadResource(&res) }).
+			api("problem.saveFile", logrus.Fields{"type": polygon.TypeResource}).
+			file(res.Path).state(fmt.Sprintf("%s/%s", polygon.TypeResource, path.Base(res.Path)), v.readState(res.Path))
 	}
 	for _, exe := range v.prob.Files.Executables.Executables {
-		errs <- v.uploadExecutable(&exe)
+		st.add("executable", exe.Source.Path, func() error { return v.uploadExecutable(&exe) }).
+			api("problem.saveFile", logrus.Fields{"type": polygon.TypeSource, "sourceType": v.sourceType(exe.Source.Type)}).
+			file(exe.Source.Path).
+			state(fmt.Sprintf("%s/%s", polygon.TypeSource, path.Base(exe.Source.Path)), v.readState(exe.Source.Path))
 	}
+	seen := make(map[string]bool)
 	for _, stat := range v.prob.Statements.Statements {
-		errs <- v.uploadStatement(&stat)
+		v.addStatement(&st, &stat, seen)
 	}
+
+	return []stage{st}
 }
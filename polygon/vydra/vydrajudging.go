@@ -2,45 +2,89 @@ package vydra
 
 import (
 	"fmt"
-	"path"
+	"strconv"
 	"strings"
 
 	"github.com/Gornak40/algolymp/polygon"
 	"github.com/sirupsen/logrus"
 )
 
-func (v *Vydra) uploadScript(testset *TestSet) error {
-	logrus.WithField("testset", testset.Name).Info("upload script")
-	gens := make([]string, 0, testset.TestCount)
-	for idx, test := range testset.Tests.Tests { // build script
-		if test.Method == "generated" {
-			line := fmt.Sprintf("%s > %d", test.Cmd, idx+1)
-			if test.FromFile != "" { // TODO: find better solution for `gen > {3-100}`
-				line = fmt.Sprintf("%s > {%d}", test.Cmd, idx+1)
-			}
-			gens = append(gens, line)
+// Consecutive tests of one multigenerator are compressed into `gen > {3-100}`.
+func buildScript(testset *TestSet) []string {
+	tests := testset.Tests.Tests
+	gens := make([]string, 0, len(tests))
+	for idx := 0; idx < len(tests); idx++ {
+		test := &tests[idx]
+		if test.Method != "generated" {
+			continue
+		}
+		if test.FromFile == "" {
+			gens = append(gens, fmt.Sprintf("%s > %d", test.Cmd, idx+1))
+
+			continue
+		}
+		last := idx
+		for last+1 < len(tests) && isNextFromFile(&tests[last], &tests[last+1]) {
+			last++
+		}
+		if last == idx {
+			gens = append(gens, fmt.Sprintf("%s > {%d}", test.Cmd, idx+1))
+		} else {
+			gens = append(gens, fmt.Sprintf("%s > {%d-%d}", test.Cmd, idx+1, last+1))
 		}
+		idx = last
 	}
-	script := strings.Join(gens, "\n")
-	if script == "" {
-		return nil
+
+	return gens
+}
+
+// Returns true if next is the next output file of the same generator run.
+func isNextFromFile(prev, next *Test) bool {
+	if next.Method != "generated" || next.Cmd != prev.Cmd {
+		return false
+	}
+	a, err := strconv.Atoi(prev.FromFile)
+	if err != nil {
+		return false
 	}
+	b, err := strconv.Atoi(next.FromFile)
 
-	return v.client.SaveScript(v.pID, testset.Name, script)
+	return err == nil && b == a+1
 }
 
-func (v *Vydra) uploadTest(testset string, idx int, test *Test) error {
-	// It's kind of experimental solution.
-	if (*test == Test{Cmd: test.Cmd, FromFile: test.FromFile, Method: "generated"}) {
-		if v.packageType == LinuxPackage || v.packageType == WindowsPackage {
-			_, err := v.streamIn.Next() // skip generated test
-			if err != nil {
-				return err
-			}
-		}
+func scriptPaths(testset string) []string {
+	name := "script"
+	if testset != polygon.DefaultTestset {
+		name += "-" + testset
+	}
 
-		return nil
+	return []string{"files/" + name, "files/" + name + ".txt", "documents/" + name, "documents/" + name + ".txt"}
+}
+
+// Original script (e.g. with FreeMarker loops) is preferred to the expanded one.
+func (v *Vydra) findScript(testset string) (string, string, bool) {
+	for _, p := range scriptPaths(testset) {
+		if data, err := v.readFile(p); err == nil {
+			return data, p, true
+		}
 	}
+
+	return "", "", false
+}
+
+func (v *Vydra) uploadScript(testset, script string) error {
+	logrus.WithField("testset", testset).Info("upload script")
+
+	return v.client.SaveScript(v.pID, testset, script)
+}
+
+// Returns false if there is nothing to upload.
+func needUploadTest(test *Test) bool {
+	// It's kind of experimental solution.
+	return *test != Test{Cmd: test.Cmd, FromFile: test.FromFile, Method: "generated"}
+}
+
+func (v *Vydra) uploadTest(testset string, idx int, test *Test, inputPath string) error {
 	logrus.WithFields(logrus.Fields{
 		"testset": testset, "idx": idx,
 		"method": test.Method, "sample": test.Sample,
@@ -57,7 +101,7 @@ func (v *Vydra) uploadTest(testset string, idx int, test *Test) error {
 		tr.Points(test.Points)
 	}
 	if test.Method == "manual" {
-		input, err := v.streamIn.Next()
+		input, err := v.readFile(inputPath)
 		if err != nil {
 			return err
 		}
@@ -67,10 +111,10 @@ func (v *Vydra) uploadTest(testset string, idx int, test *Test) error {
 	return v.client.SaveTest(tr)
 }
 
-func (v *Vydra) initGroups() error {
-	logrus.Info("init test groups")
+func (v *Vydra) initGroups(testset string) error {
+	logrus.WithField("testset", testset).Info("init test groups")
 
-	return v.client.EnableGroups(v.pID)
+	return v.client.EnableGroups(v.pID, testset)
 }
 
 func (v *Vydra) initPoints() error {
@@ -79,11 +123,17 @@ func (v *Vydra) initPoints() error {
 	return v.client.EnablePoints(v.pID)
 }
 
-func (v *Vydra) uploadGroup(testset string, group *Group) error {
+func groupDeps(group *Group) []string {
 	deps := make([]string, 0, len(group.Dependencies.Dependencies))
 	for _, d := range group.Dependencies.Dependencies {
 		deps = append(deps, d.Group)
 	}
+
+	return deps
+}
+
+func (v *Vydra) uploadGroup(testset string, group *Group) error {
+	deps := groupDeps(group)
 	logrus.WithFields(logrus.Fields{
 		"feedback":     group.FeedbackPolicy,
 		"points":       group.PointsPolicy,
@@ -117,28 +167,94 @@ func getTestsMeta(tests []Test) testsMetaInfo {
 	return ans
 }
 
-func (v *Vydra) batchJudging(errs chan error) {
+func (v *Vydra) stagesJudging() []stage {
+	stages := make([]stage, 0, 3*len(v.prob.Judging.TestSets))
 	for _, testset := range v.prob.Judging.TestSets {
-		errs <- v.uploadScript(&testset)
-		if err := v.streamIn.Init(path.Join(testset.Name, "*[^.a]")); err != nil {
-			errs <- err
+		initial := stage{name: "init " + testset.Name}
+		tests := stage{name: "tests " + testset.Name}
+		groups := stage{name: "groups " + testset.Name}
 
-			continue
-		}
+		v.addScript(&initial, &testset)
 		meta := getTestsMeta(testset.Tests.Tests)
 		if meta.enableGroups {
-			errs <- v.initGroups()
+			initial.add("enable groups", testset.Name, func() error { return v.initGroups(testset.Name) }).
+				api("problem.enableGroups", logrus.Fields{"testset": testset.Name})
 		}
 		if meta.enablePoints {
-			errs <- v.initPoints()
+			initial.add("enable points", testset.Name, v.initPoints).
+				api("problem.enablePoints", nil)
 		}
-		for idx, test := range testset.Tests.Tests {
-			errs <- v.uploadTest(testset.Name, idx+1, &test)
+		v.addTests(&tests, &testset)
+		for _, g := range testset.Groups.Groups {
+			pointsPolicy, feedbackPolicy := convertString(g.PointsPolicy), convertString(g.FeedbackPolicy)
+			groups.add("group", g.Name, func() error { return v.uploadGroup(testset.Name, &g) }).
+				api("problem.saveTestGroup", logrus.Fields{
+					"testset": testset.Name, "group": g.Name,
+					"pointsPolicy": pointsPolicy, "feedbackPolicy": feedbackPolicy,
+				}).
+				state(fmt.Sprintf("group/%s/%s", testset.Name, g.Name),
+					constState(groupState(pointsPolicy, feedbackPolicy, groupDeps(&g))))
 		}
-		if grp := testset.Groups.Groups; len(grp) != 0 {
-			for _, g := range grp {
-				errs <- v.uploadGroup(testset.Name, &g)
+		stages = append(stages, initial, tests, groups)
+	}
+
+	return stages
+}
+
+func (v *Vydra) addScript(st *stage, testset *TestSet) {
+	lines := buildScript(testset)
+	script, file, ok := v.findScript(testset.Name)
+	if !ok {
+		script = strings.Join(lines, "\n")
+	}
+	if script == "" {
+		return
+	}
+	c := st.add("script", testset.Name, func() error { return v.uploadScript(testset.Name, script) }).
+		api("problem.saveScript", logrus.Fields{
+			"testset": testset.Name, "lines": len(strings.Split(strings.TrimRight(script, "\n"), "\n")),
+		}).
+		state("script/"+testset.Name, constState(script))
+	if ok {
+		c.file(file)
+	} else {
+		c.lines = lines
+	}
+}
+
+func (v *Vydra) addTests(st *stage, testset *TestSet) {
+	v.streamIn.InitPattern(v.fsys, testset.InputPattern(), len(testset.Tests.Tests))
+	for idx, test := range testset.Tests.Tests {
+		name := fmt.Sprintf("%s/%d", testset.Name, idx+1)
+		if !needUploadTest(&test) {
+			continue
+		}
+		var input string
+		if test.Method == "manual" { // generated inputs are not needed
+			var err error
+			if input, err = v.streamIn.Path(idx + 1); err != nil {
+				st.fail("test", name, err)
+
+				continue
+			}
+		}
+		c := st.add("test", name, func() error {
+			return v.uploadTest(testset.Name, idx+1, &test, input)
+		}).api("problem.saveTest", logrus.Fields{
+			"testset": testset.Name, "testIndex": idx + 1, "method": test.Method,
+			"group": test.Group, "points": test.Points, "sample": test.Sample,
+		})
+		want := constState(testState(false, "", test.Group, test.Points, test.Sample))
+		if input != "" {
+			c.file(input)
+			read := v.readState(input)
+			want = func() (string, error) {
+				content, err := read()
+
+				return testState(true, content, test.Group, test.Points, test.Sample), err
 			}
 		}
+		c.state(fmt.Sprintf("test/%s/%d", testset.Name, idx+1), want)
 	}
+	checkUnused(st, "test", testset.Name, v.streamIn)
 }
@@ -1,7 +1,8 @@
 package vydra
 
 import (
-	"path/filepath"
+	"path"
+	"strconv"
 
 	"github.com/Gornak40/algolymp/polygon"
 	"github.com/sirupsen/logrus"
@@ -12,19 +13,24 @@ func (v *Vydra) initValidator(val *Validator) error {
 		"path": val.Source.Path, "type": val.Source.Type,
 	}).Info("init validator")
 
-	return v.client.SetValidator(v.pID, filepath.Base(val.Source.Path))
+	return v.client.SetValidator(v.pID, path.Base(val.Source.Path))
 }
 
-func (v *Vydra) initChecker(chk *Checker) error {
-	path := chk.Name
-	if path == "" {
-		path = filepath.Base(chk.Source.Path)
+func checkerName(chk *Checker) string {
+	if chk.Name != "" || chk.Source == nil {
+		return chk.Name
 	}
+
+	return path.Base(chk.Source.Path)
+}
+
+func (v *Vydra) initChecker(chk *Checker) error {
+	name := checkerName(chk)
 	logrus.WithFields(logrus.Fields{
-		"path": path, "type": chk.Type,
+		"path": name, "type": chk.Type,
 	}).Info("init checker")
 
-	return v.client.SetChecker(v.pID, path)
+	return v.client.SetChecker(v.pID, name)
 }
 
 func (v *Vydra) initInteractor(inter *Interactor) error {
@@ -32,12 +38,12 @@ func (v *Vydra) initInteractor(inter *Interactor) error {
 		"path": inter.Source.Path, "type": inter.Source.Type,
 	}).Info("init interactor")
 
-	return v.client.SetInteractor(v.pID, filepath.Base(inter.Source.Path))
+	return v.client.SetInteractor(v.pID, path.Base(inter.Source.Path))
 }
 
-func (v *Vydra) uploadValidatorTest(idx int, test *Test) error {
+func (v *Vydra) uploadValidatorTest(idx int, test *Test, inputPath string) error {
 	logrus.WithFields(logrus.Fields{"idx": idx}).Info("upload validator test")
-	input, err := v.streamIn.Next()
+	input, err := v.readFile(inputPath)
 	if err != nil {
 		return err
 	}
@@ -48,17 +54,23 @@ func (v *Vydra) uploadValidatorTest(idx int, test *Test) error {
 	return v.client.SaveValidatorTest(vtr)
 }
 
-func (v *Vydra) uploadCheckerTest(idx int, test *Test) error {
+type checkerTestPaths struct {
+	input  string
+	output string
+	answer string
+}
+
+func (v *Vydra) uploadCheckerTest(idx int, test *Test, paths *checkerTestPaths) error {
 	logrus.WithFields(logrus.Fields{"idx": idx}).Info("upload checker test")
-	input, err := v.streamIn.Next()
+	input, err := v.readFile(paths.input)
 	if err != nil {
 		return err
 	}
-	output, err := v.streamOut.Next()
+	output, err := v.readFile(paths.output)
 	if err != nil {
 		return err
 	}
-	answer, err := v.streamAns.Next()
+	answer, err := v.readFile(paths.answer)
 	if err != nil {
 		return err
 	}
@@ -70,41 +82,83 @@ func (v *Vydra) uploadCheckerTest(idx int, test *Test) error {
 	return v.client.SaveCheckerTest(ctr)
 }
 
-func (v *Vydra) batchValChk(errs chan error) {
+func (v *Vydra) checkerTest(idx int) (*checkerTestPaths, error) {
+	var paths checkerTestPaths
+	var err error
+	if paths.input, err = v.streamIn.Path(idx); err != nil {
+		return nil, err
+	}
+	if paths.output, err = v.streamOut.Path(idx); err != nil {
+		return nil, err
+	}
+	if paths.answer, err = v.streamAns.Path(idx); err != nil {
+		return nil, err
+	}
+
+	return &paths, nil
+}
+
+// Source files are uploaded in the initial stage.
+func (v *Vydra) stagesValChk() []stage {
+	assets := stage{name: "assets"}
+	tests := stage{name: "asset tests"}
 	if inter := v.prob.Assets.Interactor; inter != nil {
-		errs <- v.initInteractor(inter)
+		assets.add("interactor", inter.Source.Path, func() error { return v.initInteractor(inter) }).
+			api("problem.setInteractor", logrus.Fields{"interactor": path.Base(inter.Source.Path)})
 	}
 	if val := v.prob.Assets.Validators.Validator; val != nil {
-		errs <- v.initValidator(val)
-		if err := v.streamIn.Init("files/tests/validator-tests/*"); err != nil {
-			errs <- err
-
-			goto checker
-		}
-		for idx, test := range val.TestSet.Tests.Tests {
-			errs <- v.uploadValidatorTest(idx+1, &test)
-		}
+		assets.add("validator", val.Source.Path, func() error { return v.initValidator(val) }).
+			api("problem.setValidator", logrus.Fields{"validator": path.Base(val.Source.Path)})
+		v.addValidatorTests(&tests, val)
 	}
-checker:
 	if chk := v.prob.Assets.Checker; chk != nil {
-		errs <- v.initChecker(chk)
-		if err := v.streamIn.Init(filepath.Join(chkTests, "*[^.ao]")); err != nil {
-			errs <- err
+		assets.add("checker", checkerName(chk), func() error { return v.initChecker(chk) }).
+			api("problem.setChecker", logrus.Fields{"checker": checkerName(chk)})
+		v.addCheckerTests(&tests, chk)
+	}
 
-			return
-		}
-		if err := v.streamOut.Init(filepath.Join(chkTests, "*.o")); err != nil {
-			errs <- err
+	return []stage{assets, tests}
+}
 
-			return
-		}
-		if err := v.streamAns.Init(filepath.Join(chkTests, "*.a")); err != nil {
-			errs <- err
+func (v *Vydra) addValidatorTests(st *stage, val *Validator) {
+	tests := val.TestSet.Tests.Tests
+	v.streamIn.InitPattern(v.fsys, val.InputPattern(), len(tests))
+	for idx, test := range tests {
+		name := strconv.Itoa(idx + 1)
+		input, err := v.streamIn.Path(idx + 1)
+		if err != nil {
+			st.fail("validator test", name, err)
 
-			return
+			continue
 		}
-		for idx, test := range chk.TestSet.Tests.Tests {
-			errs <- v.uploadCheckerTest(idx+1, &test)
+		st.add("validator test", name, func() error {
+			return v.uploadValidatorTest(idx+1, &test, input)
+		}).api("problem.saveValidatorTest", logrus.Fields{
+			"testIndex": idx + 1, "testVerdict": convertString(test.Verdict),
+		}).file(input)
+	}
+	checkUnused(st, "validator test", "stream", v.streamIn)
+}
+
+func (v *Vydra) addCheckerTests(st *stage, chk *Checker) {
+	tests := chk.TestSet.Tests.Tests
+	input, output, answer := chk.TestPatterns()
+	v.streamIn.InitPattern(v.fsys, input, len(tests))
+	v.streamOut.InitPattern(v.fsys, output, len(tests))
+	v.streamAns.InitPattern(v.fsys, answer, len(tests))
+	for idx, test := range tests {
+		name := strconv.Itoa(idx + 1)
+		paths, err := v.checkerTest(idx + 1)
+		if err != nil {
+			st.fail("checker test", name, err)
+
+			continue
 		}
+		st.add("checker test", name, func() error {
+			return v.uploadCheckerTest(idx+1, &test, paths)
+		}).api("problem.saveCheckerTest", logrus.Fields{
+			"testIndex": idx + 1, "testVerdict": convertString(test.Verdict),
+		}).file(paths.input, paths.output, paths.answer)
 	}
+	checkUnused(st, "checker test", "stream", v.streamIn, v.streamOut, v.streamAns)
 }
@@ -20,6 +20,7 @@ const (
 	ModeSolutionMain      = "main"
 	ModeSolutionCorrect   = "ok"
 	ModeSolutionIncorrect = "incor"
+	ModeSolution          = "sol" // problem.xml tag, e.g. runtime-error
 	ModeSample            = "sample"
 	ModeImage             = "image" // statement resource
 )
@@ -32,6 +33,8 @@ type Wooda struct {
 	client  *polygon.Polygon
 	pID     int
 	mode    string
+	mapping *polygon.Mapping
+	tag     string
 	testIDs map[int]struct{}
 	testMex int
 }
@@ -41,11 +44,22 @@ func NewWooda(pClient *polygon.Polygon, pID int, mode string) *Wooda {
 		client:  pClient,
 		pID:     pID,
 		mode:    mode,
+		mapping: polygon.DefaultMapping(),
 		testIDs: make(map[int]struct{}),
 		testMex: 1,
 	}
 }
 
+// Override the embedded solution tags mapping.
+func (w *Wooda) UseMapping(m *polygon.Mapping) {
+	w.mapping = m
+}
+
+// Solution tag name for sol mode, the same as in problem.xml.
+func (w *Wooda) UseTag(name string) {
+	w.tag = name
+}
+
 func (w *Wooda) Resolve(path string) error {
 	logrus.WithFields(logrus.Fields{"mode": w.mode, "path": path}).Info("resolve file")
 	data, err := os.ReadFile(path)
@@ -70,6 +84,13 @@ func (w *Wooda) Resolve(path string) error {
 		return w.resolveSolution(path, file, polygon.TagCorrect)
 	case ModeSolutionIncorrect:
 		return w.resolveSolution(path, file, polygon.TagIncorrect)
+	case ModeSolution:
+		tag, err := w.mapping.SolutionTag(w.tag)
+		if err != nil {
+			return err
+		}
+
+		return w.resolveSolution(path, file, tag)
 	case ModeSample:
 		return w.resolveTest(path, file, true)
 	case ModeImage:
@@ -80,7 +101,7 @@ func (w *Wooda) Resolve(path string) error {
 }
 
 func (w *Wooda) initTMode() error {
-	ansT, err := w.client.GetTests(w.pID)
+	ansT, err := w.client.GetTests(w.pID, polygon.DefaultTestset)
 	if err != nil {
 		return err
 	}
@@ -107,17 +128,24 @@ func (w *Wooda) resolveTest(path, data string, sample bool) error {
 			return err
 		}
 	}
-	tr := polygon.NewTestRequest(w.pID, w.testMex).
-		Input(data).
-		Description(fmt.Sprintf("File \"%s\"", filepath.Base(path))).
-		UseInStatements(sample)
-	if err := w.client.SaveTest(tr); err != nil {
-		return err
+	for {
+		tr := polygon.NewTestRequest(w.pID, w.testMex).
+			Input(data).
+			Description(fmt.Sprintf("File \"%s\"", filepath.Base(path))).
+			UseInStatements(sample).
+			CheckExisting(true)
+		err := w.client.SaveTest(tr)
+		if err != nil && !errors.Is(err, polygon.ErrAlreadyExists) {
+			return err
+		}
+		idx := w.testMex
+		w.testIDs[idx] = struct{}{}
+		w.updateMex()
+		if err == nil {
+			return nil
+		}
+		logrus.WithField("index", idx).Warn("test was added by someone else, try next index")
 	}
-	w.testIDs[w.testMex] = struct{}{}
-	w.updateMex()
-
-	return nil
 }
 
 func (w *Wooda) resolveTags(data string) error {
@@ -0,0 +1,74 @@
+package wooda_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/wooda"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+	return path
+}
+
+func TestTestMode(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+	require.NoError(t, pc.SaveTest(polygon.NewTestRequest(42, 2).Input("0 0")))
+
+	w := wooda.NewWooda(pc, 42, wooda.ModeTest)
+	require.NoError(t, w.Resolve(writeFile(t, "1.in", "1 2")))
+	require.NoError(t, w.Resolve(writeFile(t, "2.in", "3 4")))
+	require.NoError(t, wooda.NewWooda(pc, 42, wooda.ModeSample).Resolve(writeFile(t, "ex", "5 6")))
+
+	tests := prob.Tests[polygon.DefaultTestset]
+	require.Len(t, tests, 4)
+	require.Equal(t, "1 2", tests[1].Input)
+	require.Equal(t, "0 0", tests[2].Input)
+	require.Equal(t, "3 4", tests[3].Input)
+	require.Equal(t, `File "2.in"`, tests[3].Description)
+	require.Equal(t, "5 6", tests[4].Input)
+	require.True(t, tests[4].UseInStatements)
+}
+
+func TestSourceModes(t *testing.T) {
+	t.Parallel()
+	srv := fakepolygon.NewServer(t)
+	prob := srv.AddProblem(42, "aplusb")
+	pc := polygon.NewPolygon(srv.Config())
+
+	require.NoError(t, wooda.NewWooda(pc, 42, wooda.ModeValidator).Resolve(writeFile(t, "val.cpp", "val")))
+	require.NoError(t, wooda.NewWooda(pc, 42, wooda.ModeChecker).Resolve(writeFile(t, "check.cpp", "chk")))
+	require.NoError(t, wooda.NewWooda(pc, 42, wooda.ModeInteractor).Resolve(writeFile(t, "inter.cpp", "int")))
+	require.NoError(t, wooda.NewWooda(pc, 42, wooda.ModeSolutionMain).Resolve(writeFile(t, "main.cpp", "sol")))
+	require.NoError(t, wooda.NewWooda(pc, 42, wooda.ModeSolutionIncorrect).Resolve(writeFile(t, "brute.py", "bad")))
+	w := wooda.NewWooda(pc, 42, wooda.ModeSolution)
+	w.UseTag("runtime-error")
+	require.NoError(t, w.Resolve(writeFile(t, "rec.cpp", "dfs")))
+	w.UseTag("magic")
+	require.ErrorIs(t, w.Resolve(writeFile(t, "magic.cpp", "")), polygon.ErrBadSolutionTag)
+	require.NoError(t, wooda.NewWooda(pc, 42, wooda.ModeTags).Resolve(writeFile(t, "tags", "dp\ngreedy")))
+	require.NoError(t, wooda.NewWooda(pc, 42, wooda.ModeImage).Resolve(writeFile(t, "pic.png", "PNG")))
+
+	require.Equal(t, "val.cpp", prob.Validator)
+	require.Equal(t, "check.cpp", prob.Checker)
+	require.Equal(t, "inter.cpp", prob.Interactor)
+	require.True(t, prob.Interactive)
+	require.Equal(t, polygon.TagMain, prob.Solutions["main.cpp"].Tag)
+	require.Equal(t, polygon.TagIncorrect, prob.Solutions["brute.py"].Tag)
+	require.Equal(t, polygon.TagRuntimeError, prob.Solutions["rec.cpp"].Tag)
+	require.Equal(t, []string{"dp", "greedy"}, prob.Tags)
+	require.Equal(t, "PNG", prob.Resources["pic.png"])
+
+	require.ErrorIs(t, wooda.NewWooda(pc, 42, "bad").Resolve(writeFile(t, "x", "")), wooda.ErrUnknownMode)
+}
@@ -0,0 +1,87 @@
+package zubr
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/vydra"
+	"github.com/sirupsen/logrus"
+)
+
+const packageType = "linux"
+
+var ErrUploadFailed = errors.New("upload failed")
+
+type Instance struct {
+	Name   string
+	Client *polygon.Polygon
+	Legacy bool // source types are converted for polygon.lksh.ru
+}
+
+type Zubr struct {
+	src     *Instance
+	dst     *Instance
+	mapping *polygon.Mapping
+	workers int
+}
+
+func NewZubr(src, dst *Instance, workers int) *Zubr {
+	return &Zubr{
+		src:     src,
+		dst:     dst,
+		mapping: polygon.DefaultMapping(),
+		workers: workers,
+	}
+}
+
+// Override the embedded solution tags and source types mapping.
+func (z *Zubr) UseMapping(m *polygon.Mapping) {
+	z.mapping = m
+}
+
+// Download the latest package of srcID and upload it into dstID.
+// The target problem is committed only if every step succeeded.
+func (z *Zubr) Migrate(srcID, dstID int) (*vydra.Report, error) {
+	prob, err := z.src.Client.GetProblem(srcID)
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{
+		"instance": z.src.Name, "name": prob.Name, "revision": prob.Revision,
+	}).Info("source problem found")
+
+	pkg, err := z.src.Client.FindPackage(srcID, prob.Revision, packageType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: build a full package of revision %d", err, prob.Revision)
+	}
+	data, err := z.src.Client.DownloadPackage(srcID, pkg.ID, pkg.Type)
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{"package": pkg.ID, "size": len(data)}).Info("download package")
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"instance": z.dst.Name, "pid": dstID, "legacy": z.dst.Legacy,
+	}).Info("upload package")
+	vyd := vydra.NewVydra(z.dst.Client, zr, dstID, z.dst.Legacy, z.workers)
+	vyd.UseMapping(z.mapping)
+	rep, err := vyd.Upload()
+	if err != nil {
+		return rep, err
+	}
+	if rep.Failed() != 0 {
+		return rep, fmt.Errorf("%w: %d steps failed", ErrUploadFailed, rep.Failed())
+	}
+
+	message := fmt.Sprintf("migrated from %s problem %d revision %d", z.src.Name, srcID, prob.Revision)
+	logrus.WithField("message", message).Info("commit target problem")
+
+	return rep, z.dst.Client.Commit(dstID, false, message)
+}
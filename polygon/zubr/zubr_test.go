@@ -0,0 +1,66 @@
+package zubr_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Gornak40/algolymp/internal/fakepolygon"
+	"github.com/Gornak40/algolymp/polygon"
+	"github.com/Gornak40/algolymp/polygon/zubr"
+	"github.com/stretchr/testify/require"
+)
+
+func packageZip(t *testing.T, dir string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	require.NoError(t, zw.AddFS(os.DirFS(dir)))
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+	src := fakepolygon.NewServer(t)
+	src.AddProblem(1, "aplusb")
+	dst := fakepolygon.NewServer(t)
+	prob := dst.AddProblem(2, "aplusb")
+
+	z := zubr.NewZubr(
+		&zubr.Instance{Name: "lksh", Client: polygon.NewPolygon(src.Config()), Legacy: true},
+		&zubr.Instance{Name: "codeforces", Client: polygon.NewPolygon(dst.Config())},
+		4,
+	)
+	_, err := z.Migrate(1, 2)
+	require.ErrorIs(t, err, polygon.ErrNoPackage)
+
+	src.AddPackage(1, "linux", packageZip(t, "../vydra/testdata/aplusb"))
+	rep, err := z.Migrate(1, 2)
+	require.NoError(t, err)
+	require.Zero(t, rep.Failed())
+	require.Len(t, prob.Tests[polygon.DefaultTestset], 3)
+	require.Equal(t, "cpp.g++17", prob.Solutions["main.cpp"].SourceType)
+	require.Equal(t, []string{"migrated from lksh problem 1 revision 1"}, prob.Commits)
+}
+
+func TestMigrateFailed(t *testing.T) {
+	t.Parallel()
+	src := fakepolygon.NewServer(t)
+	src.AddProblem(1, "aplusb")
+	src.AddPackage(1, "linux", packageZip(t, "../vydra/testdata/aplusb"))
+	dst := fakepolygon.NewServer(t)
+	prob := dst.AddProblem(2, "aplusb")
+	dst.Fail("problem.saveSolution", 1, `{"status":"FAILED","comment":"file: broken"}`)
+
+	z := zubr.NewZubr(
+		&zubr.Instance{Name: "lksh", Client: polygon.NewPolygon(src.Config())},
+		&zubr.Instance{Name: "codeforces", Client: polygon.NewPolygon(dst.Config())},
+		4,
+	)
+	_, err := z.Migrate(1, 2)
+	require.ErrorIs(t, err, zubr.ErrUploadFailed)
+	require.Empty(t, prob.Commits)
+}